@@ -10,6 +10,13 @@ type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	PDF      PDFConfig      `mapstructure:"pdf"`
 	ChromeDP ChromeDPConfig `mapstructure:"chromedp"`
+	Jobs     JobsConfig     `mapstructure:"jobs"`
+	Webhooks WebhooksConfig `mapstructure:"webhooks"`
+	Links    LinksConfig    `mapstructure:"links"`
+	Validation ValidationConfig `mapstructure:"validation"`
+	Pricing  PricingConfig  `mapstructure:"pricing"`
+	Payments PaymentsConfig `mapstructure:"payments"`
+	Invoice  InvoiceConfig  `mapstructure:"invoice"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
 }
 
@@ -17,6 +24,10 @@ type ServerConfig struct {
 	Port        string `mapstructure:"port"`
 	Host        string `mapstructure:"host"`
 	TemplateDir string `mapstructure:"template_dir"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-* headers
+	// are believed. Empty trusts no one.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type PDFConfig struct {
@@ -25,6 +36,53 @@ type PDFConfig struct {
 	PageFormat    string        `mapstructure:"page_format"`
 	Orientation   string        `mapstructure:"orientation"`
 	DefaultMargin MarginConfig  `mapstructure:"margin"`
+	WorkspacePath string        `mapstructure:"workspace_path"`
+	StorageMode   string        `mapstructure:"storage_mode"`
+	StorageBackend string       `mapstructure:"storage_backend"`
+	S3            S3Config      `mapstructure:"s3"`
+	Images        ImagesConfig  `mapstructure:"images"`
+	Currency      string        `mapstructure:"currency"`
+	Locale        string        `mapstructure:"locale"`
+	Queue         RenderQueueConfig `mapstructure:"queue"`
+	Cleanup       CleanupConfig     `mapstructure:"cleanup"`
+}
+
+// S3Config configures the S3-compatible storage backend.
+type S3Config struct {
+	Endpoint        string        `mapstructure:"endpoint"`
+	Region          string        `mapstructure:"region"`
+	Bucket          string        `mapstructure:"bucket"`
+	AccessKeyID     string        `mapstructure:"access_key_id"`
+	SecretAccessKey string        `mapstructure:"secret_access_key"`
+	SessionToken    string        `mapstructure:"session_token"`
+	Prefix          string        `mapstructure:"prefix"`
+	UsePathStyle    bool          `mapstructure:"use_path_style"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	PresignExpiry   time.Duration `mapstructure:"presign_expiry"`
+}
+
+// ImagesConfig configures page images captured alongside PDFs.
+type ImagesConfig struct {
+	Format         string `mapstructure:"format"`
+	DPI            int    `mapstructure:"dpi"`
+	MaxDPI         int    `mapstructure:"max_dpi"`
+	Quality        int    `mapstructure:"quality"`
+	MaxPages       int    `mapstructure:"max_pages"`
+	CoverThumbnail bool   `mapstructure:"cover_thumbnail"`
+	CoverDPI       int    `mapstructure:"cover_dpi"`
+}
+
+type CleanupConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	MaxStorage string        `mapstructure:"max_storage"`
+	DryRun     bool          `mapstructure:"dry_run"`
+}
+
+type RenderQueueConfig struct {
+	Workers    int           `mapstructure:"workers"`
+	Capacity   int           `mapstructure:"capacity"`
+	RetryAfter time.Duration `mapstructure:"retry_after"`
 }
 
 type MarginConfig struct {
@@ -38,6 +96,122 @@ type ChromeDPConfig struct {
 	Timeout           time.Duration `mapstructure:"timeout"`
 	DisableWebSecurity bool          `mapstructure:"disable_web_security"`
 	Headless          bool          `mapstructure:"headless"`
+	Pool              BrowserPoolConfig `mapstructure:"pool"`
+	Readiness         ReadinessConfig   `mapstructure:"readiness"`
+}
+
+type ReadinessConfig struct {
+	Timeout             time.Duration `mapstructure:"timeout"`
+	NetworkIdle         time.Duration `mapstructure:"network_idle"`
+	FailOnMissingAssets bool          `mapstructure:"fail_on_missing_assets"`
+}
+
+type BrowserPoolConfig struct {
+	Size              int           `mapstructure:"size"`
+	MaxTabsPerBrowser int           `mapstructure:"max_tabs_per_browser"`
+	MaxBrowserAge     time.Duration `mapstructure:"max_browser_age"`
+	AcquireTimeout    time.Duration `mapstructure:"acquire_timeout"`
+}
+
+type JobsConfig struct {
+	Workers     int           `mapstructure:"workers"`
+	Capacity    int           `mapstructure:"capacity"`
+	Retention   time.Duration `mapstructure:"retention"`
+	BusyRetries int           `mapstructure:"busy_retries"`
+}
+
+type WebhooksConfig struct {
+	Secret         string            `mapstructure:"secret"`
+	Timeout        time.Duration     `mapstructure:"timeout"`
+	MaxAttempts    int               `mapstructure:"max_attempts"`
+	InitialBackoff time.Duration     `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration     `mapstructure:"max_backoff"`
+	LogSize        int               `mapstructure:"log_size"`
+	APIKeys        []APIKeyCallback  `mapstructure:"api_keys"`
+	// AllowPrivateTargets lets callbacks reach loopback and private
+	// networks, for local development only.
+	AllowPrivateTargets bool `mapstructure:"allow_private_targets"`
+}
+
+// APIKeyCallback registers a default callback URL for requests sent with
+// the given X-API-Key header.
+type APIKeyCallback struct {
+	Key         string `mapstructure:"key"`
+	CallbackURL string `mapstructure:"callback_url"`
+}
+
+// LinksConfig configures signed public download links for stored PDFs.
+type LinksConfig struct {
+	Secret     string        `mapstructure:"secret"`
+	BaseURL    string        `mapstructure:"base_url"`
+	DefaultTTL time.Duration `mapstructure:"default_ttl"`
+	MaxTTL     time.Duration `mapstructure:"max_ttl"`
+}
+
+// ValidationConfig sets the severity of each business rule: "error",
+// "warning" or "off". Rules that are not listed keep their default.
+type ValidationConfig struct {
+	Rules map[string]string `mapstructure:"rules"`
+}
+
+// PricingConfig sets the defaults of the pricing engine. Requests override
+// the mode, basis, markup and GST rate in their "pricing" object.
+type PricingConfig struct {
+	Mode          string            `mapstructure:"mode"`
+	MarkupPercent float64           `mapstructure:"markup_percent"`
+	GSTPercent    float64           `mapstructure:"gst_percent"`
+	Basis         map[string]string `mapstructure:"basis"`
+	TCS           TCSConfig         `mapstructure:"tcs"`
+}
+
+// TCSConfig sets the tax collected at source on overseas tour packages: Rate
+// up to Threshold and HighRate on the amount above it.
+type TCSConfig struct {
+	RatePercent     float64 `mapstructure:"rate_percent"`
+	HighRatePercent float64 `mapstructure:"high_rate_percent"`
+	Threshold       string  `mapstructure:"threshold"`
+}
+
+// PaymentsConfig sets the installment plan generated for requests without
+// installments, and the days due dates may not fall on.
+type PaymentsConfig struct {
+	Schedule []InstallmentRuleConfig `mapstructure:"schedule"`
+	Weekend  []string                `mapstructure:"weekend"`
+	Holidays []string                `mapstructure:"holidays"`
+}
+
+// InstallmentRuleConfig is one installment of the default plan; see
+// models.InstallmentRule.
+type InstallmentRuleConfig struct {
+	Name       string  `mapstructure:"name"`
+	Percent    float64 `mapstructure:"percent"`
+	Amount     string  `mapstructure:"amount"`
+	DaysBefore *int    `mapstructure:"days_before"`
+}
+
+// InvoiceConfig sets how proforma invoice numbers are formed and the seller,
+// tax and bank details printed on them. Requests override the details in
+// their "invoice" object.
+type InvoiceConfig struct {
+	Prefix       string            `mapstructure:"prefix"`
+	Digits       int               `mapstructure:"digits"`
+	ValidityDays int               `mapstructure:"validity_days"`
+	SellerGSTIN  string            `mapstructure:"seller_gstin"`
+	SACCode      string            `mapstructure:"sac_code"`
+	SACCodes     map[string]string `mapstructure:"sac_codes"`
+	Bank         BankConfig        `mapstructure:"bank"`
+	Terms        []string          `mapstructure:"terms"`
+}
+
+// BankConfig is the account invoices are paid into; see models.BankDetails.
+type BankConfig struct {
+	AccountName   string `mapstructure:"account_name"`
+	AccountNumber string `mapstructure:"account_number"`
+	BankName      string `mapstructure:"bank_name"`
+	Branch        string `mapstructure:"branch"`
+	IFSC          string `mapstructure:"ifsc"`
+	SWIFT         string `mapstructure:"swift"`
+	UPI           string `mapstructure:"upi"`
 }
 
 type LoggingConfig struct {
@@ -56,19 +230,98 @@ func LoadConfig() error {
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.template_dir", "./templates")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.trusted_proxies", []string{})
 	
 	viper.SetDefault("pdf.storage_path", "./storage/pdfs")
 	viper.SetDefault("pdf.max_file_age", "168h") 
+	viper.SetDefault("pdf.storage_mode", "version")
+	viper.SetDefault("pdf.currency", "INR")
+	viper.SetDefault("pdf.locale", "")
+	viper.SetDefault("pdf.storage_backend", "local")
+	viper.SetDefault("pdf.s3.region", "us-east-1")
+	viper.SetDefault("pdf.s3.use_path_style", true)
+	viper.SetDefault("pdf.s3.timeout", "30s")
+	viper.SetDefault("pdf.s3.presign_expiry", "15m")
+	viper.SetDefault("pdf.images.format", "png")
+	viper.SetDefault("pdf.images.dpi", 96)
+	viper.SetDefault("pdf.images.max_dpi", 300)
+	viper.SetDefault("pdf.images.quality", 85)
+	viper.SetDefault("pdf.images.max_pages", 50)
+	viper.SetDefault("pdf.images.cover_thumbnail", true)
+	viper.SetDefault("pdf.images.cover_dpi", 48)
 	viper.SetDefault("pdf.page_format", "A4")
 	viper.SetDefault("pdf.orientation", "portrait")
 	viper.SetDefault("pdf.margin.top", "0.5in")
 	viper.SetDefault("pdf.margin.bottom", "0.5in")
 	viper.SetDefault("pdf.margin.left", "0.5in")
 	viper.SetDefault("pdf.margin.right", "0.5in")
+	viper.SetDefault("pdf.workspace_path", "./storage/render")
+	viper.SetDefault("pdf.queue.workers", 2)
+	viper.SetDefault("pdf.queue.capacity", 20)
+	viper.SetDefault("pdf.queue.retry_after", "10s")
+	viper.SetDefault("pdf.cleanup.enabled", true)
+	viper.SetDefault("pdf.cleanup.interval", "1h")
+	viper.SetDefault("pdf.cleanup.max_storage", "")
+	viper.SetDefault("pdf.cleanup.dry_run", false)
 	
 	viper.SetDefault("chromedp.timeout", "30s")
 	viper.SetDefault("chromedp.disable_web_security", true)
 	viper.SetDefault("chromedp.headless", true)
+	viper.SetDefault("chromedp.pool.size", 2)
+	viper.SetDefault("chromedp.pool.max_tabs_per_browser", 100)
+	viper.SetDefault("chromedp.pool.max_browser_age", "1h")
+	viper.SetDefault("chromedp.pool.acquire_timeout", "30s")
+	viper.SetDefault("chromedp.readiness.timeout", "10s")
+	viper.SetDefault("chromedp.readiness.network_idle", "300ms")
+	viper.SetDefault("chromedp.readiness.fail_on_missing_assets", true)
+	
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.capacity", 100)
+	viper.SetDefault("jobs.retention", "1h")
+	viper.SetDefault("jobs.busy_retries", 30)
+	
+	viper.SetDefault("webhooks.secret", "")
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.max_attempts", 5)
+	viper.SetDefault("webhooks.initial_backoff", "1s")
+	viper.SetDefault("webhooks.max_backoff", "1m")
+	viper.SetDefault("webhooks.log_size", 1000)
+	viper.SetDefault("webhooks.allow_private_targets", false)
+	
+	viper.SetDefault("links.secret", "")
+	viper.SetDefault("links.base_url", "")
+	viper.SetDefault("links.default_ttl", "24h")
+	viper.SetDefault("links.max_ttl", "720h")
+	
+	viper.SetDefault("pricing.mode", "reconcile")
+	viper.SetDefault("pricing.markup_percent", 0)
+	viper.SetDefault("pricing.gst_percent", 5)
+	viper.SetDefault("pricing.basis.flights", "per_pax")
+	viper.SetDefault("pricing.basis.hotels", "per_group")
+	viper.SetDefault("pricing.basis.activities", "per_pax")
+	viper.SetDefault("pricing.basis.transfers", "per_group")
+	viper.SetDefault("pricing.tcs.rate_percent", 5)
+	viper.SetDefault("pricing.tcs.high_rate_percent", 20)
+	viper.SetDefault("pricing.tcs.threshold", "1000000")
+	
+	viper.SetDefault("payments.schedule", []map[string]interface{}{
+		{"name": "Advance Payment", "percent": 30},
+		{"name": "Second Installment", "percent": 50, "days_before": 45},
+		{"name": "Balance Payment", "days_before": 15},
+	})
+	viper.SetDefault("payments.weekend", []string{"saturday", "sunday"})
+	viper.SetDefault("payments.holidays", []string{})
+	
+	viper.SetDefault("invoice.prefix", "PI")
+	viper.SetDefault("invoice.digits", 4)
+	viper.SetDefault("invoice.validity_days", 7)
+	viper.SetDefault("invoice.seller_gstin", "")
+	viper.SetDefault("invoice.sac_code", "998552")
+	viper.SetDefault("invoice.terms", []string{
+		"This is a proforma invoice and not a tax invoice. A tax invoice will be issued on receipt of payment.",
+		"Prices are subject to availability at the time of booking.",
+	})
 	
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (h *PDFHandler) ListPDFs(c *gin.Context) {
+	filter, err := parsePDFListFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid query parameters",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	list, err := h.fileService.ListPDFs(c.Request.Context(), filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list PDFs")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to list PDFs",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "PDFs retrieved successfully",
+		Data:    list,
+	})
+}
+
+func (h *PDFHandler) GetPDF(c *gin.Context) {
+	info, _, ok := h.lookupPDF(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "PDF retrieved successfully",
+		Data:    info,
+	})
+}
+
+func (h *PDFHandler) DownloadPDF(c *gin.Context) {
+	info, key, ok := h.lookupPDF(c)
+	if !ok {
+		return
+	}
+
+	// Backends that can serve files themselves, such as S3, take the
+	// download off this instance when the client asks for a redirect.
+	if c.Query("redirect") == "true" {
+		url, err := h.fileService.PresignURL(c.Request.Context(), key)
+		if err == nil {
+			c.Redirect(http.StatusTemporaryRedirect, url)
+			return
+		}
+		if !errors.Is(err, services.ErrPresignNotSupported) {
+			logrus.WithError(err).WithField("id", info.ID).Warn("Failed to presign PDF download, streaming instead")
+		}
+	}
+
+	h.streamPDF(c, info, key)
+}
+
+// streamPDF sends a stored PDF as an attachment named after its readable
+// file name.
+func (h *PDFHandler) streamPDF(c *gin.Context, info *models.FileInfoResponse, key string) {
+	reader, size, err := h.fileService.OpenPDF(c.Request.Context(), key)
+	if errors.Is(err, services.ErrPDFNotFound) {
+		respondPDFNotFound(c)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to read PDF file",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, size, "application/pdf", reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", info.FileName),
+	})
+}
+
+func (h *PDFHandler) DeletePDF(c *gin.Context) {
+	err := h.fileService.DeletePDF(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, services.ErrPDFNotFound) {
+		respondPDFNotFound(c)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to delete PDF",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "PDF deleted successfully",
+	})
+}
+
+func (h *PDFHandler) lookupPDF(c *gin.Context) (*models.FileInfoResponse, string, bool) {
+	info, key, err := h.fileService.GetPDF(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, services.ErrPDFNotFound) {
+		respondPDFNotFound(c)
+		return nil, "", false
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to load PDF")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to load PDF",
+			Message: err.Error(),
+		})
+		return nil, "", false
+	}
+	return info, key, true
+}
+
+func respondPDFNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, models.ErrorResponse{
+		Error:   "PDF not found",
+		Message: fmt.Sprintf("No PDF with id %s", c.Param("id")),
+	})
+}
+
+// parsePDFListFilter reads page, page_size, customer, destination and the
+// inclusive from/to generation dates (YYYY-MM-DD) from the query string.
+func parsePDFListFilter(c *gin.Context) (models.PDFListFilter, error) {
+	filter := models.PDFListFilter{
+		Customer:    c.Query("customer"),
+		Destination: c.Query("destination"),
+		Page:        1,
+		PageSize:    defaultPageSize,
+	}
+
+	if page := c.Query("page"); page != "" {
+		value, err := strconv.Atoi(page)
+		if err != nil || value < 1 {
+			return filter, fmt.Errorf("page must be a positive integer")
+		}
+		filter.Page = value
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		value, err := strconv.Atoi(pageSize)
+		if err != nil || value < 1 || value > maxPageSize {
+			return filter, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+		filter.PageSize = value
+	}
+
+	if from := c.Query("from"); from != "" {
+		value, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("from must be a date in YYYY-MM-DD format")
+		}
+		filter.From = value
+	}
+
+	if to := c.Query("to"); to != "" {
+		value, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return filter, fmt.Errorf("to must be a date in YYYY-MM-DD format")
+		}
+		filter.To = value.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPageImages lists PNG or JPEG images of a stored PDF's pages, rendering
+// them on first request for a format and DPI.
+func (h *PDFHandler) GetPageImages(c *gin.Context) {
+	opts, err := parseImageOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid image options",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	images, err := h.pdfService.PageImages(c.Request.Context(), c.Param("id"), opts)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrPDFNotFound):
+		respondPDFNotFound(c)
+		return
+	case errors.Is(err, services.ErrNoDocumentSource):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Page images unavailable",
+			Message: "This PDF was stored before page images were supported; regenerate it to enable them",
+		})
+		return
+	default:
+		h.respondGenerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Page images retrieved successfully",
+		Data:    images,
+	})
+}
+
+// parseImageOptions reads format, dpi and quality from the query string.
+// Missing values fall back to pdf.images.
+func parseImageOptions(c *gin.Context) (models.ImageOptions, error) {
+	opts := models.ImageOptions{Format: c.Query("format")}
+
+	if dpi := c.Query("dpi"); dpi != "" {
+		value, err := strconv.Atoi(dpi)
+		if err != nil {
+			return opts, fmt.Errorf("dpi must be an integer")
+		}
+		opts.DPI = value
+	}
+
+	if quality := c.Query("quality"); quality != "" {
+		value, err := strconv.Atoi(quality)
+		if err != nil {
+			return opts, fmt.Errorf("quality must be an integer")
+		}
+		opts.Quality = value
+	}
+
+	return opts, nil
+}
+
+// GetPageImage serves one stored page image or cover thumbnail. Images are
+// replaced when their PDF is overwritten, so clients revalidate with the ETag
+// instead of caching them outright.
+func (h *PDFHandler) GetPageImage(c *gin.Context) {
+	reader, object, err := h.fileService.OpenImage(c.Request.Context(), c.Param("id"), c.Param("name"))
+	if errors.Is(err, services.ErrPDFNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Image not found",
+			Message: "The requested page image does not exist",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to read page image",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	etag := imageETag(object)
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, reader, nil)
+}
+
+// imageETag identifies one version of a stored image by its size and
+// modification time, which change whenever it is re-rendered.
+func imageETag(object services.ObjectInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, object.ModTime.UnixNano(), object.Size)
+}
+
+// etagMatches reports whether an If-None-Match header names etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/services"
+)
+
+func TestImageETag(t *testing.T) {
+	modTime := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	etag := imageETag(services.ObjectInfo{Size: 1024, ModTime: modTime})
+
+	if etag != imageETag(services.ObjectInfo{Key: "other", Size: 1024, ModTime: modTime}) {
+		t.Error("imageETag() depends on more than size and modification time")
+	}
+	if etag == imageETag(services.ObjectInfo{Size: 1024, ModTime: modTime.Add(time.Second)}) {
+		t.Error("imageETag() did not change with the modification time")
+	}
+	if etag == imageETag(services.ObjectInfo{Size: 2048, ModTime: modTime}) {
+		t.Error("imageETag() did not change with the size")
+	}
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{etag, true},
+		{"W/" + etag, true},
+		{`"other", ` + etag, true},
+		{"*", true},
+		{`"other"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (h *PDFHandler) submitJob(c *gin.Context, request *models.ItineraryRequest, callbackURL string) {
+	job, err := h.jobService.Submit(c.Request.Context(), request, callbackURL)
+	if err != nil {
+		h.respondGenerationError(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v1/jobs/%s", job.ID))
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: "PDF generation job queued",
+		Data:    job,
+	})
+}
+
+func (h *PDFHandler) GetJob(c *gin.Context) {
+	job, ok := h.lookupJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Job is %s", job.Status),
+		Data:    job,
+	})
+}
+
+func (h *PDFHandler) DownloadJob(c *gin.Context) {
+	job, ok := h.lookupJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status != models.JobStatusDone || job.Result == nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   services.ErrJobNotReady.Error(),
+			Message: fmt.Sprintf("Job is %s", job.Status),
+		})
+		return
+	}
+
+	// The PDF outlives the job record only until cleanup removes it, so
+	// look it up afresh rather than trusting the stored key.
+	info, key, err := h.fileService.GetPDF(c.Request.Context(), job.Result.ID)
+	if errors.Is(err, services.ErrPDFNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "PDF not found",
+			Message: fmt.Sprintf("The PDF for job %s is no longer stored", job.ID),
+		})
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("jobID", job.ID).Error("Failed to load job PDF")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to load PDF",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.streamPDF(c, info, key)
+}
+
+func (h *PDFHandler) GetJobDeliveries(c *gin.Context) {
+	job, ok := h.lookupJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Webhook deliveries retrieved",
+		Data:    h.webhookService.DeliveriesForJob(job.ID),
+	})
+}
+
+func (h *PDFHandler) lookupJob(c *gin.Context) (*models.PDFJob, bool) {
+	job, err := h.jobService.Get(c.Param("id"))
+	if errors.Is(err, services.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Job not found",
+			Message: fmt.Sprintf("No job with id %s", c.Param("id")),
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Failed to load job",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return job, true
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateDownloadLink mints a signed, expiring public URL for a stored PDF.
+func (h *PDFHandler) CreateDownloadLink(c *gin.Context) {
+	var request models.DownloadLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request format",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if request.ExpiresIn != "" {
+		var err error
+		ttl, err = time.ParseDuration(request.ExpiresIn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid expiresIn",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	link, err := h.linkService.Create(c.Request.Context(), c.Param("id"), ttl, request.OneTime)
+	if err != nil {
+		h.respondLinkError(c, err)
+		return
+	}
+	link.URL = publicBaseURL(c) + h.linkService.SignedPath(link)
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Download link created",
+		Data:    link,
+	})
+}
+
+func (h *PDFHandler) GetDownloadLink(c *gin.Context) {
+	link, err := h.linkService.Get(c.Request.Context(), c.Param("linkId"))
+	if err != nil {
+		h.respondLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Download link retrieved successfully",
+		Data:    link,
+	})
+}
+
+func (h *PDFHandler) RevokeDownloadLink(c *gin.Context) {
+	link, err := h.linkService.Revoke(c.Request.Context(), c.Param("linkId"))
+	if err != nil {
+		h.respondLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Download link revoked",
+		Data:    link,
+	})
+}
+
+// SharedDownload is the public route behind a signed link. It needs no API
+// access; the signature, expiry and link state are checked before the PDF
+// is streamed.
+func (h *PDFHandler) SharedDownload(c *gin.Context) {
+	link, err := h.linkService.Redeem(c.Request.Context(), c.Param("linkId"), c.Request.URL.Query())
+	if err != nil {
+		h.respondLinkError(c, err)
+		return
+	}
+
+	info, key, err := h.fileService.GetPDF(c.Request.Context(), link.PDFID)
+	if err != nil {
+		h.respondLinkError(c, err)
+		return
+	}
+
+	h.streamPDF(c, info, key)
+}
+
+func (h *PDFHandler) respondLinkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrLinksDisabled):
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Download links unavailable",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrLinkTTL):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid expiresIn",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrLinkInvalid):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Invalid download link",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrLinkExpired),
+		errors.Is(err, services.ErrLinkRevoked),
+		errors.Is(err, services.ErrLinkUsed):
+		c.JSON(http.StatusGone, models.ErrorResponse{
+			Error:   "Download link no longer valid",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrLinkNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Download link not found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrPDFNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "PDF not found",
+			Message: err.Error(),
+		})
+	default:
+		logrus.WithError(err).Error("Failed to process download link")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "Download link failed",
+			Message: err.Error(),
+		})
+	}
+}
+
+// publicBaseURL prefers links.base_url so links and preview assets point at
+// the public host rather than whichever instance served the request.
+// Forwarded headers are only believed from server.trusted_proxies.
+func publicBaseURL(c *gin.Context) string {
+	if base := config.AppConfig.Links.BaseURL; base != "" {
+		return strings.TrimRight(base, "/")
+	}
+
+	scheme, host := "http", c.Request.Host
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if fromTrustedProxy(c) {
+		if forwarded := strings.ToLower(firstForwarded(c.GetHeader("X-Forwarded-Proto"))); forwarded == "http" || forwarded == "https" {
+			scheme = forwarded
+		}
+		if forwarded := firstForwarded(c.GetHeader("X-Forwarded-Host")); validForwardedHost(forwarded) {
+			host = forwarded
+		}
+	}
+	return scheme + "://" + host
+}
+
+// fromTrustedProxy reports whether the connection itself comes from one of
+// server.trusted_proxies.
+func fromTrustedProxy(c *gin.Context) bool {
+	remote := net.ParseIP(c.RemoteIP())
+	if remote == nil {
+		return false
+	}
+	for _, proxy := range config.AppConfig.Server.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.Equal(remote) {
+				return true
+			}
+			continue
+		}
+		if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(remote) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstForwarded returns the value added by the proxy nearest the client.
+func firstForwarded(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
+
+func validForwardedHost(host string) bool {
+	if host == "" {
+		return false
+	}
+	u, err := url.Parse("//" + host)
+	return err == nil && u.Host == host && u.Path == "" && u.User == nil
+}
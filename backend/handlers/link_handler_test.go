@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublicBaseURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		baseURL        string
+		trustedProxies []string
+		remoteAddr     string
+		headers        map[string]string
+		want           string
+	}{
+		{
+			name:    "configured base URL wins",
+			baseURL: "https://pdf.vigovia.com/",
+			headers: map[string]string{"X-Forwarded-Host": "evil.example"},
+			want:    "https://pdf.vigovia.com",
+		},
+		{
+			name:       "forwarded headers ignored without trusted proxies",
+			remoteAddr: "10.0.0.5:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example"},
+			want:       "http://api.internal",
+		},
+		{
+			name:           "forwarded headers ignored from an untrusted peer",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "203.0.113.9:1234",
+			headers:        map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example"},
+			want:           "http://api.internal",
+		},
+		{
+			name:           "trusted proxy by CIDR",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:1234",
+			headers:        map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "pdf.vigovia.com, proxy.internal"},
+			want:           "https://pdf.vigovia.com",
+		},
+		{
+			name:           "trusted proxy by address",
+			trustedProxies: []string{"192.168.1.10"},
+			remoteAddr:     "192.168.1.10:1234",
+			headers:        map[string]string{"X-Forwarded-Proto": "HTTPS"},
+			want:           "https://api.internal",
+		},
+		{
+			name:           "malformed forwarded values ignored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:1234",
+			headers:        map[string]string{"X-Forwarded-Proto": "javascript", "X-Forwarded-Host": "evil.example/path"},
+			want:           "http://api.internal",
+		},
+	}
+
+	original := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = original })
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig = &config.Config{
+				Server: config.ServerConfig{TrustedProxies: tt.trustedProxies},
+				Links:  config.LinksConfig{BaseURL: tt.baseURL},
+			}
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "http://api.internal/api/v1/preview", nil)
+			if tt.remoteAddr != "" {
+				c.Request.RemoteAddr = tt.remoteAddr
+			}
+			for name, value := range tt.headers {
+				c.Request.Header.Set(name, value)
+			}
+
+			if got := publicBaseURL(c); got != tt.want {
+				t.Errorf("publicBaseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		os.Exit(1)
+	}
+	logrus.SetLevel(logrus.ErrorLevel)
+	gin.SetMode(gin.TestMode)
+
+	workspace, err := os.MkdirTemp("", "vigovia-handlers-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create workspace:", err)
+		os.Exit(1)
+	}
+
+	cfg := config.AppConfig
+	cfg.Server.TemplateDir = filepath.Join("..", "templates")
+	cfg.PDF.StorageBackend = services.StorageBackendMemory
+	cfg.PDF.StoragePath = filepath.Join(workspace, "pdfs")
+	cfg.PDF.WorkspacePath = filepath.Join(workspace, "render")
+
+	code := m.Run()
+	os.RemoveAll(workspace)
+	os.Exit(code)
+}
+
+// newTestPDFHandler wires the real services on in-memory storage. Requests
+// that fail validation never reach Chrome, so none is needed.
+func newTestPDFHandler(t *testing.T) *PDFHandler {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := NewPDFHandler(ctx)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		h.Shutdown(context.Background())
+	})
+	return h
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/KrishKoria/Vigovia/models"
@@ -12,15 +16,48 @@ import (
 )
 
 type PDFHandler struct {
-	pdfService  *services.PDFService
-	fileService *services.FileService
+	pdfService     *services.PDFService
+	fileService    *services.FileService
+	jobService     *services.JobService
+	webhookService *services.WebhookService
+	cleanupService *services.CleanupService
+	linkService    *services.LinkService
 }
 
-func NewPDFHandler() *PDFHandler {
+// NewPDFHandler wires up the PDF services. ctx lives as long as the server
+// and stops background work when cancelled.
+func NewPDFHandler(ctx context.Context) (*PDFHandler, error) {
+	fileService, err := services.NewFileService()
+	if err != nil {
+		return nil, err
+	}
+	webhookService, err := services.NewWebhookService()
+	if err != nil {
+		return nil, err
+	}
+	pdfService := services.NewPDFService(fileService)
+	linkService := services.NewLinkService(fileService)
 	return &PDFHandler{
-		pdfService:  services.NewPDFService(),
-		fileService: services.NewFileService(),
+		pdfService:     pdfService,
+		fileService:    fileService,
+		jobService:     services.NewJobService(pdfService, webhookService),
+		webhookService: webhookService,
+		cleanupService: services.NewCleanupService(ctx, fileService, linkService),
+		linkService:    linkService,
+	}, nil
+}
+
+func (h *PDFHandler) Shutdown(ctx context.Context) error {
+	if err := h.cleanupService.Close(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to stop PDF cleanup")
+	}
+	if err := h.jobService.Close(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to drain PDF jobs")
 	}
+	if err := h.webhookService.Close(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to finish webhook deliveries")
+	}
+	return h.pdfService.Close(ctx)
 }
 
 func (h *PDFHandler) GenerateItinerary(c *gin.Context) {
@@ -45,35 +82,41 @@ func (h *PDFHandler) GenerateItinerary(c *gin.Context) {
 		"daysCount": len(request.Itinerary.Days),
 		"flightsCount": len(request.Flights),
 		"hotelsCount": len(request.Hotels),
-		"hasPayment": request.Payment.TotalAmount != "",
+		"hasPayment": !request.Payment.TotalAmount.IsZero(),
 	}).Info("Received PDF generation request")
 	
-	response, err := h.pdfService.GenerateItinerary(&request)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to generate PDF")
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "PDF generation failed",
-			Message: err.Error(),
+	callbackURL := request.CallbackURL
+	if callbackURL == "" {
+		callbackURL = h.webhookService.CallbackForAPIKey(c.GetHeader("X-API-Key"))
+	}
+	
+	// A callback only makes sense for a job, so it implies async mode.
+	async := c.Query("async") == "true" || callbackURL != ""
+	store := c.Query("store") != "false"
+	
+	if async && !store {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "store=false cannot be combined with async generation or callbacks",
 		})
 		return
 	}
 	
-	if c.Query("download") == "true" {
-		pdfData, err := h.fileService.ReadPDF(response.FilePath)
-		if err != nil {
-			logrus.WithError(err).Error("Failed to read generated PDF file")
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-				Error:   "Failed to read PDF file",
-				Message: err.Error(),
-			})
-			return
-		}
-		
-		c.Header("Content-Type", "application/pdf")
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", response.FileName))
-		c.Header("Content-Length", fmt.Sprintf("%d", len(pdfData)))
-		
-		c.Data(http.StatusOK, "application/pdf", pdfData)
+	if async {
+		h.submitJob(c, &request, callbackURL)
+		return
+	}
+	
+	// Without storage there is nothing to refer back to, so the PDF itself
+	// is the response.
+	if c.Query("download") == "true" || !store {
+		h.streamItinerary(c, &request, store)
+		return
+	}
+	
+	response, err := h.pdfService.GenerateItinerary(c.Request.Context(), &request)
+	if err != nil {
+		h.respondGenerationError(c, err)
 		return
 	}
 	
@@ -83,6 +126,142 @@ func (h *PDFHandler) GenerateItinerary(c *gin.Context) {
 		Data:    response,
 	})
 }
+
+// Trailers sent after a streamed PDF, since its headers go out before the
+// document is finished.
+const (
+	PDFIDTrailer        = "X-Vigovia-PDF-ID"
+	RenderStatusTrailer = "X-Vigovia-Render-Status"
+)
+
+// streamItinerary sends the PDF to the client while Chrome produces it.
+func (h *PDFHandler) streamItinerary(c *gin.Context, request *models.ItineraryRequest, store bool) {
+	sink := &pdfResponseSink{c: c}
+	response, err := h.pdfService.StreamItinerary(c.Request.Context(), request, sink, store)
+	
+	// A render abandoned by a cancelled request may still be writing;
+	// close the sink so it cannot touch the response after we return.
+	if !sink.close() {
+		if err == nil {
+			err = errors.New("renderer produced an empty PDF")
+		}
+		h.respondGenerationError(c, err)
+		return
+	}
+	
+	switch {
+	case errors.Is(err, services.ErrPDFNotStored):
+		logrus.WithError(err).Error("Streamed PDF could not be stored")
+		c.Writer.Header().Set(RenderStatusTrailer, "unsaved")
+	case err != nil:
+		// The status line is already sent; the trailer and the truncated
+		// body are all the client gets.
+		logrus.WithError(err).Error("PDF stream failed after it started")
+		c.Writer.Header().Set(RenderStatusTrailer, "failed")
+	default:
+		c.Writer.Header().Set(RenderStatusTrailer, "complete")
+		if response.ID != "" {
+			c.Writer.Header().Set(PDFIDTrailer, response.ID)
+		}
+	}
+}
+
+// pdfResponseSink writes a streamed PDF to the response. Headers are
+// committed with the first chunk, so failures before that can still be
+// reported as JSON.
+type pdfResponseSink struct {
+	c        *gin.Context
+	mu       sync.Mutex
+	filename string
+	started  bool
+	closed   bool
+}
+
+var errSinkClosed = errors.New("response already finished")
+
+func (s *pdfResponseSink) Start(filename string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filename = filename
+}
+
+func (s *pdfResponseSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if s.closed {
+		return 0, errSinkClosed
+	}
+	if !s.started {
+		s.started = true
+		header := s.c.Writer.Header()
+		header.Set("Content-Type", "application/pdf")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", s.filename))
+		header.Set("Trailer", PDFIDTrailer+", "+RenderStatusTrailer)
+		s.c.Status(http.StatusOK)
+	}
+	
+	n, err := s.c.Writer.Write(p)
+	s.c.Writer.Flush()
+	return n, err
+}
+
+// close refuses further writes and reports whether the response was started.
+func (s *pdfResponseSink) close() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.started
+}
+
+// respondGenerationError maps service errors to HTTP statuses shared by the
+// synchronous and asynchronous generation endpoints.
+func (h *PDFHandler) respondGenerationError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondValidationError(c, validationErr.Errors, validationErr.Warnings)
+	case errors.Is(err, services.ErrRenderQueueFull):
+		c.Header("Retry-After", strconv.Itoa(int(h.pdfService.RetryAfter().Seconds())))
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Error:   "Too many concurrent PDF requests",
+			Message: "The render queue is full, please retry later",
+		})
+	case errors.Is(err, services.ErrJobQueueFull):
+		c.Header("Retry-After", strconv.Itoa(int(h.pdfService.RetryAfter().Seconds())))
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Error:   "Too many queued PDF jobs",
+			Message: "The job queue is full, please retry later",
+		})
+	case errors.Is(err, services.ErrJobsShutdown):
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "Service unavailable",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrWebhooksUnsigned), errors.Is(err, services.ErrCallbackNotAllowed):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid callback",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidPageLayout):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid page layout",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidImageOptions):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid image options",
+			Message: err.Error(),
+		})
+	default:
+		logrus.WithError(err).Error("Failed to generate PDF")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "PDF generation failed",
+			Message: err.Error(),
+		})
+	}
+}
+
 func (h *PDFHandler) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
@@ -91,6 +270,9 @@ func (h *PDFHandler) HealthCheck(c *gin.Context) {
 			"status":    "ok",
 			"timestamp": time.Now().Format(time.RFC3339),
 			"version":   "1.0.0",
+			"browserPool": h.pdfService.PoolStats(),
+			"renderQueue": h.pdfService.QueueStats(),
+			"cleanup": h.cleanupService.Stats(),
 		},
 	})
 }
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestSink() (*pdfResponseSink, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	return &pdfResponseSink{c: c}, recorder
+}
+
+func TestPDFResponseSink(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunks      []string
+		wantStarted bool
+		wantBody    string
+	}{
+		{"nothing written", nil, false, ""},
+		{"chunks", []string{"%PDF-", "1.4"}, true, "%PDF-1.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, recorder := newTestSink()
+			sink.Start("trip.pdf")
+			for _, chunk := range tt.chunks {
+				if _, err := sink.Write([]byte(chunk)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			if started := sink.close(); started != tt.wantStarted {
+				t.Errorf("close() = %v, want %v", started, tt.wantStarted)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if tt.wantStarted {
+				if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/pdf" {
+					t.Errorf("status %d, Content-Type %q", recorder.Code, recorder.Header().Get("Content-Type"))
+				}
+				if got := recorder.Header().Get("Content-Disposition"); got != `attachment; filename="trip.pdf"` {
+					t.Errorf("Content-Disposition = %q", got)
+				}
+			}
+
+			if _, err := sink.Write([]byte("late")); !errors.Is(err, errSinkClosed) {
+				t.Errorf("Write() after close error = %v, want %v", err, errSinkClosed)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf("body after a late write = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPDFResponseSinkConcurrentClose(t *testing.T) {
+	sink, _ := newTestSink()
+	sink.Start("trip.pdf")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := sink.Write([]byte("x")); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	sink.close()
+	wg.Wait()
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PreviewItinerary returns the HTML that would be printed for a request,
+// without spending a Chrome render. ?print=true applies the print stylesheet.
+func (h *PDFHandler) PreviewItinerary(c *gin.Context) {
+	var request models.ItineraryRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.WithError(err).Error("Failed to bind JSON request")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	html, err := h.pdfService.PreviewItinerary(&request, services.PreviewOptions{
+		AssetBaseURL: publicBaseURL(c),
+		Print:        c.Query("print") == "true",
+	})
+	if err != nil {
+		h.respondGenerationError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidateItinerary runs every check a generation request goes through,
+// without rendering, and reports all failing fields at once.
+func (h *PDFHandler) ValidateItinerary(c *gin.Context) {
+	var request models.ItineraryRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.WithError(err).Error("Failed to bind JSON request")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	problems, warnings := h.pdfService.Validate(&request)
+	if len(problems) > 0 {
+		respondValidationError(c, problems, warnings)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:  true,
+		Message:  "Request is valid",
+		Warnings: warnings,
+	})
+}
+
+func respondValidationError(c *gin.Context, problems, warnings []models.APIError) {
+	c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+		Success:  false,
+		Message:  "Validation failed",
+		Errors:   problems,
+		Warnings: warnings,
+	})
+}
+
+// QuoteItinerary prices a request from its line items without rendering it.
+func (h *PDFHandler) QuoteItinerary(c *gin.Context) {
+	var request models.ItineraryRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.WithError(err).Error("Failed to bind JSON request")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	breakdown, warnings, err := h.pdfService.Quote(&request)
+	if err != nil {
+		h.respondGenerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success:  true,
+		Message:  "Price computed",
+		Data:     breakdown,
+		Warnings: warnings,
+	})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/gin-gonic/gin"
+)
+
+// sampleBody reads a request from test_samples and lets edit change the
+// decoded JSON before it is sent.
+func sampleBody(t *testing.T, edit func(request map[string]interface{})) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("..", "test_samples", "test_sample.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request map[string]interface{}
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(request)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// object walks nested JSON objects and arrays, e.g. "itinerary", "days", 1.
+func object(value interface{}, path ...interface{}) map[string]interface{} {
+	for _, step := range path {
+		switch step := step.(type) {
+		case string:
+			value = value.(map[string]interface{})[step]
+		case int:
+			value = value.([]interface{})[step]
+		}
+	}
+	return value.(map[string]interface{})
+}
+
+func postJSON(t *testing.T, handler gin.HandlerFunc, path string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := gin.New()
+	router.POST(path, handler)
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func errorFields(errors []models.APIError) map[string]models.APIError {
+	fields := make(map[string]models.APIError, len(errors))
+	for _, apiError := range errors {
+		fields[apiError.Field] = apiError
+	}
+	return fields
+}
+
+func TestValidateItinerary(t *testing.T) {
+	h := newTestPDFHandler(t)
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name:       "valid request",
+			body:       sampleBody(t, nil),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "malformed JSON",
+			body:       []byte(`{"customer":`),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "nested activity field",
+			body: sampleBody(t, func(request map[string]interface{}) {
+				delete(object(request, "itinerary", "days", 1, "activities", 0), "location")
+			}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"itinerary.days[1].activities[0].location"},
+		},
+		{
+			name: "every failing field reported",
+			body: sampleBody(t, func(request map[string]interface{}) {
+				object(request, "customer")["email"] = "not-an-email"
+				delete(object(request, "itinerary", "days", 0, "activities", 1), "name")
+				delete(object(request, "hotels", 0), "city")
+			}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{
+				"customer.email",
+				"itinerary.days[0].activities[1].name",
+				"hotels[0].city",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := postJSON(t, h.ValidateItinerary, "/api/v1/validate", tt.body)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tt.wantStatus == http.StatusBadRequest {
+				return
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Success != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("success = %v, want %v", response.Success, tt.wantStatus == http.StatusOK)
+			}
+
+			fields := errorFields(response.Errors)
+			if len(fields) != len(tt.wantFields) {
+				t.Errorf("errors = %+v, want fields %v", response.Errors, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if apiError, ok := fields[field]; !ok || apiError.Code != "VALIDATION_ERROR" || apiError.Message == "" {
+					t.Errorf("error for %s = %+v, want a VALIDATION_ERROR with a message", field, apiError)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateItineraryValidationError(t *testing.T) {
+	h := newTestPDFHandler(t)
+	body := sampleBody(t, func(request map[string]interface{}) {
+		delete(object(request, "itinerary", "days", 1, "activities", 0), "location")
+	})
+
+	recorder := postJSON(t, h.GenerateItinerary, "/api/v1/generate-pdf", body)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusUnprocessableEntity, recorder.Body)
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"success", "message", "errors"} {
+		if _, ok := response[key]; !ok {
+			t.Errorf("response %s has no %q", recorder.Body, key)
+		}
+	}
+	if _, ok := response["data"]; ok {
+		t.Errorf("a rejected request returned data: %s", response["data"])
+	}
+
+	var apiResponse models.APIResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &apiResponse); err != nil {
+		t.Fatal(err)
+	}
+	want := models.APIError{
+		Field:   "itinerary.days[1].activities[0].location",
+		Message: "location is required",
+		Code:    "VALIDATION_ERROR",
+	}
+	if apiResponse.Success || apiResponse.Message != "Validation failed" || len(apiResponse.Errors) != 1 || apiResponse.Errors[0] != want {
+		t.Errorf("response = %+v, want one error %+v", apiResponse, want)
+	}
+}
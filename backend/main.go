@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
 
 	"github.com/KrishKoria/Vigovia/config"
 	"github.com/KrishKoria/Vigovia/handlers"
@@ -23,19 +28,53 @@ func main() {
 	}
 	
 	router := gin.New()
+	if err := router.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid server.trusted_proxies: %v", err)
+	}
 	
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.ErrorHandlingMiddleware())
 	router.Use(gin.Recovery())
 	
-	setupRoutes(router)
+	// ctx ends on SIGINT or SIGTERM and stops background work with it.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	
+	pdfHandler, err := handlers.NewPDFHandler(ctx)
+	if err != nil {
+		log.Fatalf("Failed to initialise PDF handler: %v", err)
+	}
+	setupRoutes(router, pdfHandler)
 	
 	port := fmt.Sprintf(":%s", config.AppConfig.Server.Port)
-	logrus.WithField("port", port).Info("Starting server")
+	server := &http.Server{
+		Addr:    port,
+		Handler: router,
+	}
+	
+	go func() {
+		logrus.WithField("port", port).Info("Starting server")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Fatal("Failed to start server")
+		}
+	}()
+	
+	<-ctx.Done()
+	stop()
+	
+	logrus.Info("Shutting down server")
+	
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.AppConfig.Server.ShutdownTimeout)
+	defer cancel()
 	
-	if err := router.Run(port); err != nil {
-		logrus.WithError(err).Fatal("Failed to start server")
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Error("Server forced to shutdown")
 	}
+	if err := pdfHandler.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Error("Failed to release PDF resources")
+	}
+	
+	logrus.Info("Server stopped")
 }
 
 func setupLogging() {
@@ -63,9 +102,7 @@ func setupLogging() {
 	logrus.Info("Logging configured")
 }
 
-func setupRoutes(router *gin.Engine) {
-	pdfHandler := handlers.NewPDFHandler()
-	
+func setupRoutes(router *gin.Engine, pdfHandler *handlers.PDFHandler) {
 	router.Static("/static", "./static")
 	
 	v1 := router.Group("/api/v1")
@@ -73,8 +110,29 @@ func setupRoutes(router *gin.Engine) {
 		v1.GET("/health", pdfHandler.HealthCheck)
 		
 		v1.POST("/generate-pdf", pdfHandler.GenerateItinerary)
+		v1.POST("/preview", pdfHandler.PreviewItinerary)
+		v1.POST("/validate", pdfHandler.ValidateItinerary)
+		v1.POST("/quote", pdfHandler.QuoteItinerary)
+		
+		v1.GET("/jobs/:id", pdfHandler.GetJob)
+		v1.GET("/jobs/:id/download", pdfHandler.DownloadJob)
+		v1.GET("/jobs/:id/deliveries", pdfHandler.GetJobDeliveries)
+		
+		v1.GET("/pdfs", pdfHandler.ListPDFs)
+		v1.GET("/pdfs/:id", pdfHandler.GetPDF)
+		v1.GET("/pdfs/:id/download", pdfHandler.DownloadPDF)
+		v1.DELETE("/pdfs/:id", pdfHandler.DeletePDF)
+		v1.GET("/pdfs/:id/images", pdfHandler.GetPageImages)
+		v1.GET("/pdfs/:id/images/:name", pdfHandler.GetPageImage)
+		v1.POST("/pdfs/:id/links", pdfHandler.CreateDownloadLink)
+		
+		v1.GET("/links/:linkId", pdfHandler.GetDownloadLink)
+		v1.DELETE("/links/:linkId", pdfHandler.RevokeDownloadLink)
 	}
 	
+	// Signed public downloads live outside the API group.
+	router.GET("/shared/:linkId", pdfHandler.SharedDownload)
+	
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "Vigovia PDF Generation API",
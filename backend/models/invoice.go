@@ -0,0 +1,70 @@
+package models
+
+// Document types rendered from an itinerary request
+const (
+	DocumentItinerary       = "itinerary"
+	DocumentProformaInvoice = "proforma_invoice"
+)
+
+// InvoiceOptions represents the party, tax and bank details of a proforma
+// invoice. Empty fields fall back to the invoice section of config.yaml.
+type InvoiceOptions struct {
+	SellerGSTIN   string       `json:"sellerGstin" validate:"omitempty,gstin"`
+	BuyerGSTIN    string       `json:"buyerGstin" validate:"omitempty,gstin"`
+	BuyerAddress  string       `json:"buyerAddress"`
+	PlaceOfSupply string       `json:"placeOfSupply"`
+	SACCode       string       `json:"sacCode" validate:"omitempty,numeric,len=6"`
+	ValidityDays  int          `json:"validityDays" validate:"omitempty,min=1"`
+	Bank          *BankDetails `json:"bank"`
+	Terms         []string     `json:"terms"`
+}
+
+// BankDetails represents the account an invoice is paid into
+type BankDetails struct {
+	AccountName   string `json:"accountName"`
+	AccountNumber string `json:"accountNumber" validate:"omitempty,numeric"`
+	BankName      string `json:"bankName"`
+	Branch        string `json:"branch"`
+	IFSC          string `json:"ifsc" validate:"omitempty,ifsc"`
+	SWIFT         string `json:"swift" validate:"omitempty,bic"`
+	UPI           string `json:"upi"`
+}
+
+// Invoice represents a proforma invoice ready to render. Number is empty
+// for previews, which are never issued a number.
+type Invoice struct {
+	Number        string        `json:"number"`
+	Date          string        `json:"date"`
+	ValidUntil    string        `json:"validUntil"`
+	SellerGSTIN   string        `json:"sellerGstin"`
+	BuyerGSTIN    string        `json:"buyerGstin,omitempty"`
+	BuyerAddress  string        `json:"buyerAddress,omitempty"`
+	PlaceOfSupply string        `json:"placeOfSupply,omitempty"`
+	Interstate    bool          `json:"interstate"`
+	Lines         []InvoiceLine `json:"lines"`
+	Subtotal      Money         `json:"subtotal"`
+	Discount      Money         `json:"discount"`
+	Taxable       Money         `json:"taxable"`
+	Taxes         []TaxLine     `json:"taxes"`
+	Tax           Money         `json:"tax"`
+	Total         Money         `json:"total"`
+	TCS           Money         `json:"tcs"`
+	Payable       Money         `json:"payable"`
+	Bank          BankDetails   `json:"bank"`
+	Terms         []string      `json:"terms"`
+}
+
+// InvoiceLine represents one taxable line of an invoice with its SAC code
+type InvoiceLine struct {
+	Description string `json:"description"`
+	SACCode     string `json:"sacCode"`
+	Items       int    `json:"items,omitempty"`
+	Amount      Money  `json:"amount"`
+}
+
+// TaxLine represents one GST component charged on an invoice
+type TaxLine struct {
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent"`
+	Amount  Money   `json:"amount"`
+}
@@ -6,8 +6,8 @@ type ItineraryRequest struct {
 	Customer       Customer         `json:"customer" validate:"required"`
 	Trip           Trip             `json:"trip" validate:"required"`
 	Itinerary      Itinerary        `json:"itinerary" validate:"required"`
-	Flights        []Flight         `json:"flights"`
-	Hotels         []Hotel          `json:"hotels"`
+	Flights        []Flight         `json:"flights" validate:"dive"`
+	Hotels         []Hotel          `json:"hotels" validate:"dive"`
 	Payment        Payment          `json:"payment"`
 	Config         PDFConfig        `json:"config"`
 	CompanyInfo    CompanyInfo      `json:"companyInfo"`
@@ -15,6 +15,12 @@ type ItineraryRequest struct {
 	ScopeOfService []ServiceScope   `json:"scopeOfService"`
 	Inclusions     []Inclusion      `json:"inclusions"`
 	VisaDetails    VisaDetails      `json:"visaDetails"`
+	CallbackURL    string           `json:"callbackUrl" validate:"omitempty,url"`
+	Currency       string           `json:"currency" validate:"omitempty,iso4217"`
+	Locale         string           `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	Pricing        *PricingOptions  `json:"pricing"`
+	DocumentType   string           `json:"documentType" validate:"omitempty,oneof=itinerary proforma_invoice"`
+	Invoice        *InvoiceOptions  `json:"invoice"`
 }
 
 // Customer represents customer information
@@ -30,26 +36,26 @@ type Trip struct {
 	Destination   string `json:"destination" validate:"required"`
 	StartDate     string `json:"startDate" validate:"required"`
 	EndDate       string `json:"endDate" validate:"required"`
-	Duration      string `json:"duration" validate:"required"`
+	Duration      string `json:"duration"`
 	Travelers     int    `json:"travelers" validate:"required,min=1"`
 	DepartureFrom string `json:"departureFrom"`
 }
 
 // Itinerary represents the complete itinerary with days
 type Itinerary struct {
-	Days []Day `json:"days" validate:"required,min=1"`
+	Days []Day `json:"days" validate:"required,min=1,dive"`
 }
 
 // Day represents a single day in the itinerary
 type Day struct {
 	DayNumber  int        `json:"dayNumber" validate:"required,min=1"`
-	Date       string     `json:"date" validate:"required"`
+	Date       string     `json:"date"`
 	Title      string     `json:"title" validate:"required"`
-	Activities []Activity `json:"activities" validate:"required,min=1"`
-	Transfers  []Transfer `json:"transfers"`
-	Flights    []Flight   `json:"flights"`
+	Activities []Activity `json:"activities" validate:"required,min=1,dive"`
+	Transfers  []Transfer `json:"transfers" validate:"dive"`
+	Flights    []Flight   `json:"flights" validate:"dive"`
 	Image      string     `json:"image"`
-	Timeline   []Timeline `json:"timeline"`
+	Timeline   []Timeline `json:"timeline" validate:"dive"`
 }
 
 type Activity struct {
@@ -58,7 +64,7 @@ type Activity struct {
 	Description string  `json:"description" validate:"required"`
 	Location    string  `json:"location" validate:"required"`
 	Duration    string  `json:"duration" validate:"required"`
-	Price       float64 `json:"price" validate:"min=0"`
+	Price       Money   `json:"price" validate:"omitempty,money"`
 	Image       string  `json:"image"`
 	Type        string  `json:"type"`
 	Time        string  `json:"time"`
@@ -73,7 +79,7 @@ type Transfer struct {
 	PickupTime  string  `json:"pickupTime" validate:"required"`
 	DropoffTime string  `json:"dropoffTime" validate:"required"`
 	Duration    string  `json:"duration" validate:"required"`
-	Price       float64 `json:"price" validate:"min=0"`
+	Price       Money   `json:"price" validate:"omitempty,money"`
 	Capacity    int     `json:"capacity" validate:"min=1"`
 }
 
@@ -89,7 +95,7 @@ type Flight struct {
 	Departure    string  `json:"departure" validate:"required"`
 	Arrival      string  `json:"arrival" validate:"required"`
 	Class        string  `json:"class" validate:"required"`
-	Price        float64 `json:"price" validate:"min=0"`
+	Price        Money   `json:"price" validate:"omitempty,money"`
 }
 
 // Hotel represents hotel booking information
@@ -97,26 +103,48 @@ type Hotel struct {
 	City         string  `json:"city" validate:"required"`
 	CheckIn      string  `json:"checkIn" validate:"required"`
 	CheckOut     string  `json:"checkOut" validate:"required"`
-	Nights       int    `json:"nights" validate:"min=1"`
+	Nights       int    `json:"nights" validate:"omitempty,min=1"`
 	HotelName    string  `json:"hotelName" validate:"required"`
 	RoomType     string  `json:"roomType"`
-	PricePerNight float64 `json:"pricePerNight" validate:"min=0"`
+	PricePerNight Money   `json:"pricePerNight" validate:"omitempty,money"`
 }
 
 type Payment struct {
-	TotalAmount   string        `json:"totalAmount" validate:"required"`
-	TCS           string        `json:"tcs"`
-	AdvanceAmount string        `json:"advanceAmount,omitempty"`
-	BalanceAmount string        `json:"balanceAmount,omitempty"`
+	TotalAmount   Money         `json:"totalAmount" validate:"required,money"`
+	TCS           Money         `json:"tcs" validate:"omitempty,money"`
+	AdvanceAmount Money         `json:"advanceAmount,omitzero"`
+	BalanceAmount Money         `json:"balanceAmount,omitzero"`
 	Status        string        `json:"status,omitempty"`
-	Installments  []Installment `json:"installments" validate:"required,min=1"`
+	BookingDate   string        `json:"bookingDate,omitempty"`
+	Schedule      []InstallmentRule `json:"schedule,omitempty" validate:"dive"`
+	Installments  []Installment `json:"installments" validate:"required,min=1,dive"`
 }
 
+// Installment and payment statuses
+const (
+	PaymentPaid          = "paid"
+	PaymentPending       = "pending"
+	PaymentOverdue       = "overdue"
+	PaymentPartiallyPaid = "partially_paid"
+)
+
 // Installment represents a payment installment
 type Installment struct {
 	InstallmentName string `json:"installment" validate:"required"`
-	Amount          string `json:"amount" validate:"required"`
+	Amount          Money  `json:"amount" validate:"required,money"`
 	DueDate         string `json:"dueDate" validate:"required"`
+	Status          string `json:"status,omitempty" validate:"omitempty,oneof=paid pending overdue"`
+	PaidOn          string `json:"paidOn,omitempty"`
+}
+
+// InstallmentRule represents one installment of a generated payment plan: a
+// percentage of the total, a fixed amount, or the remainder when neither is
+// given. It is due on booking unless DaysBefore departure is set.
+type InstallmentRule struct {
+	Name       string  `json:"name" validate:"required"`
+	Percent    float64 `json:"percent" validate:"omitempty,gt=0,max=100"`
+	Amount     Money   `json:"amount" validate:"omitempty,money"`
+	DaysBefore *int    `json:"daysBefore" validate:"omitempty,min=0"`
 }
 
 // Timeline represents timeline activities for a day
@@ -126,20 +154,59 @@ type Timeline struct {
 }
 
 // PDFConfig represents PDF generation configuration
+// Section toggles are pointers so an omitted field keeps the section at its
+// default (shown)
 type PDFConfig struct {
-	IncludeFlights    bool          `json:"includeFlights"`
-	IncludeHotels     bool          `json:"includeHotels"`
-	IncludeActivities bool          `json:"includeActivities"`
-	IncludePayments   bool          `json:"includePayments"`
+	IncludeFlights        *bool         `json:"includeFlights"`
+	IncludeHotels         *bool         `json:"includeHotels"`
+	IncludeActivities     *bool         `json:"includeActivities"`
+	IncludePayments       *bool         `json:"includePayments"`
+	IncludeImportantNotes *bool         `json:"includeImportantNotes"`
+	IncludeScope          *bool         `json:"includeScope"`
+	IncludeInclusions     *bool         `json:"includeInclusions"`
+	IncludeVisaDetails    *bool         `json:"includeVisaDetails"`
+	IncludePricing        *bool         `json:"includePricing"`
 	PageFormat        string        `json:"pageFormat"`
 	Orientation       string        `json:"orientation"`
+	Margin            PageMargin    `json:"margin"`
 	CustomBranding    CustomBranding `json:"customBranding"`
+	StorageMode       string        `json:"storageMode" validate:"omitempty,oneof=version overwrite"`
+	Images            *ImageOptions `json:"images"`
+	AmountInWords     string        `json:"amountInWords" validate:"omitempty,oneof=en hi off"`
+}
+
+// ImageOptions represents a request for page images rendered with the PDF
+type ImageOptions struct {
+	Format  string `json:"format" validate:"omitempty,oneof=png jpeg"`
+	DPI     int    `json:"dpi" validate:"omitempty,min=24"`
+	Quality int    `json:"quality" validate:"omitempty,min=1,max=100"`
+}
+
+// PageMargin represents per-request page margins as CSS lengths (e.g. "10mm")
+type PageMargin struct {
+	Top    string `json:"top"`
+	Bottom string `json:"bottom"`
+	Left   string `json:"left"`
+	Right  string `json:"right"`
+}
+
+// SectionToggles represents which document sections are rendered
+type SectionToggles struct {
+	Flights        bool `json:"flights"`
+	Hotels         bool `json:"hotels"`
+	Activities     bool `json:"activities"`
+	Payments       bool `json:"payments"`
+	ImportantNotes bool `json:"importantNotes"`
+	Scope          bool `json:"scope"`
+	Inclusions     bool `json:"inclusions"`
+	VisaDetails    bool `json:"visaDetails"`
+	Pricing        bool `json:"pricing"`
 }
 
 // CustomBranding represents custom branding options
 type CustomBranding struct {
-	PrimaryColor string `json:"primaryColor"`
-	AccentColor  string `json:"accentColor"`
+	PrimaryColor string `json:"primaryColor" validate:"omitempty,hexcolor"`
+	AccentColor  string `json:"accentColor" validate:"omitempty,hexcolor"`
 	LogoURL      string `json:"logoUrl"`
 	CompanyName  string `json:"companyName"`
 }
@@ -153,6 +220,8 @@ type TemplateData struct {
 	Hotels         []Hotel        `json:"hotels"`
 	Payment        Payment        `json:"payment"`
 	Config         PDFConfig      `json:"config"`
+	Sections       SectionToggles `json:"sections"`
+	Theme          Theme          `json:"theme"`
 	ImportantNotes []ImportantNote `json:"importantNotes"`
 	ScopeOfService []ServiceScope  `json:"scopeOfService"`
 	Inclusions     []Inclusion     `json:"inclusions"`
@@ -160,9 +229,28 @@ type TemplateData struct {
 	CompanyInfo    CompanyInfo     `json:"companyInfo"`
 	ContactInfo    ContactInfo     `json:"contactInfo"`
 	CompanyLogo    string          `json:"companyLogo"`
+	Currency       string          `json:"currency"`
+	Locale         string          `json:"locale"`
+	Pricing        *CostBreakdown  `json:"pricing,omitempty"`
+	Invoice        *Invoice        `json:"invoice,omitempty"`
 	GeneratedAt    time.Time      `json:"generatedAt"`
 }
 
+// Theme represents the resolved brand palette and identity used by templates
+type Theme struct {
+	PrimaryColor     string `json:"primaryColor"`
+	AccentColor      string `json:"accentColor"`
+	AccentLightColor string `json:"accentLightColor"`
+	AccentFaintColor string `json:"accentFaintColor"`
+	HighlightColor   string `json:"highlightColor"`
+	SurfaceColor     string `json:"surfaceColor"`
+	BorderColor      string `json:"borderColor"`
+	LogoURL          string `json:"logoUrl"`
+	CompanyName      string `json:"companyName"`
+	WhiteLabel       bool   `json:"whiteLabel"`
+	ShowCompanyInfo  bool   `json:"showCompanyInfo"`
+}
+
 // CompanyInfo represents company information for footer
 type CompanyInfo struct {
 	Name             string           `json:"name"`
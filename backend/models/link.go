@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DownloadLinkRequest represents the options for minting a signed download link
+type DownloadLinkRequest struct {
+	ExpiresIn string `json:"expiresIn"`
+	OneTime   bool   `json:"oneTime"`
+}
+
+// DownloadLink represents a signed, expiring public download URL for a stored PDF
+type DownloadLink struct {
+	ID        string     `json:"id"`
+	PDFID     string     `json:"pdf_id"`
+	URL       string     `json:"url,omitempty"`
+	OneTime   bool       `json:"one_time"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
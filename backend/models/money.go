@@ -0,0 +1,333 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// maxMoneyDigits keeps every amount, and sums of thousands of them, well
+// inside int64.
+const maxMoneyDigits = 15
+
+var (
+	ErrInvalidAmount  = errors.New("invalid amount")
+	ErrMoneyPrecision = errors.New("amount has more decimal places than its currency allows")
+
+	moneyPattern = regexp.MustCompile(`^(-)?([0-9]+)(?:\.([0-9]+))?$`)
+)
+
+// Money represents an exact amount as a whole number of minor units
+// (paise, cents) so sums never pick up float rounding errors. Scale is the
+// number of decimal places in Minor: the digits the amount was written with
+// until it is rescaled to its currency's minor unit.
+type Money struct {
+	minor    int64
+	scale    int
+	currency string
+	set      bool
+	raw      string
+}
+
+// moneyJSON represents the structured form of an amount, with the amount
+// written either as a decimal or in the currency's minor units
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount,omitempty"`
+	Minor    *int64          `json:"minor,omitempty"`
+	Currency string          `json:"currency,omitempty"`
+}
+
+// ParseMoney reads a decimal amount such as "1500", "2,890,000.00" or
+// "-250.5". Grouping commas and spaces are ignored; currency symbols,
+// exponents and anything else are rejected.
+func ParseMoney(s string) (Money, error) {
+	cleaned := strings.NewReplacer(",", "", " ", "").Replace(strings.TrimSpace(s))
+	match := moneyPattern.FindStringSubmatch(cleaned)
+	if match == nil || len(match[2])+len(match[3]) > maxMoneyDigits {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+
+	minor, err := strconv.ParseInt(match[2]+match[3], 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+	if match[1] != "" {
+		minor = -minor
+	}
+	return Money{minor: minor, scale: len(match[3]), set: true}, nil
+}
+
+// NewMoney returns minor units of currency, using the currency's own
+// decimal places (2 for INR, 0 for JPY).
+func NewMoney(minor int64, code string) (Money, error) {
+	decimals, err := CurrencyDecimals(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: minor, scale: decimals, currency: strings.ToUpper(code), set: true}, nil
+}
+
+// CurrencyDecimals returns the number of decimal places of an ISO 4217
+// currency's minor unit.
+func CurrencyDecimals(code string) (int, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 0, fmt.Errorf("unknown currency %q", code)
+	}
+	decimals, _ := currency.Standard.Rounding(unit)
+	return decimals, nil
+}
+
+// Minor returns the amount in units of 10^-Scale.
+func (m Money) Minor() int64 { return m.minor }
+
+// Scale returns the number of decimal places in Minor.
+func (m Money) Scale() int { return m.scale }
+
+// Currency returns the ISO 4217 code given with the amount, or "" when the
+// amount uses the request's currency.
+func (m Money) Currency() string { return m.currency }
+
+// IsSet reports whether an amount was given at all.
+func (m Money) IsSet() bool { return m.set || m.raw != "" }
+
+// IsZero reports whether the amount is missing or zero.
+func (m Money) IsZero() bool { return m.raw == "" && m.minor == 0 }
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool { return m.minor < 0 }
+
+// Valid reports whether the amount could be read. Invalid amounts keep their
+// input so validation can report it.
+func (m Money) Valid() bool { return m.raw == "" }
+
+// Float64 returns the amount for display; totals should use Add instead.
+func (m Money) Float64() float64 {
+	value := float64(m.minor)
+	for i := 0; i < m.scale; i++ {
+		value /= 10
+	}
+	return value
+}
+
+// String writes the amount as a plain decimal with its scale, e.g.
+// "2890000.00", or returns an unreadable input unchanged.
+func (m Money) String() string {
+	if !m.Valid() {
+		return m.raw
+	}
+	if !m.set {
+		return ""
+	}
+
+	digits := strconv.FormatInt(m.minor, 10)
+	sign := ""
+	if m.minor < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if m.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= m.scale {
+		digits = strings.Repeat("0", m.scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-m.scale] + "." + digits[len(digits)-m.scale:]
+}
+
+// WithCurrency returns the amount tagged with a currency code.
+func (m Money) WithCurrency(code string) Money {
+	m.currency = strings.ToUpper(code)
+	return m
+}
+
+// Rescale returns the amount with exactly decimals places, failing when that
+// would drop non-zero digits, e.g. 10.005 to 2 places.
+func (m Money) Rescale(decimals int) (Money, error) {
+	for m.scale < decimals {
+		m.minor *= 10
+		m.scale++
+	}
+	for m.scale > decimals {
+		if m.minor%10 != 0 {
+			return Money{}, fmt.Errorf("%w: %s", ErrMoneyPrecision, m)
+		}
+		m.minor /= 10
+		m.scale--
+	}
+	return m, nil
+}
+
+// Add returns the exact sum at the larger of the two scales. The currency of
+// either operand is kept; callers must not mix currencies.
+func (m Money) Add(other Money) Money {
+	a, b := align(m, other)
+	a.minor += b.minor
+	a.set = m.set || other.set
+	if a.currency == "" {
+		a.currency = b.currency
+	}
+	return a
+}
+
+// Sub returns m - other; see Add.
+func (m Money) Sub(other Money) Money {
+	return m.Add(other.Neg())
+}
+
+// Neg returns the amount with its sign flipped.
+func (m Money) Neg() Money {
+	m.minor = -m.minor
+	return m
+}
+
+// Mul returns the amount multiplied by a whole number, such as a nightly
+// rate times nights.
+func (m Money) Mul(n int64) Money {
+	m.minor *= n
+	return m
+}
+
+// Percent returns percent of the amount at the same scale, rounded half
+// away from zero to the last digit. The rate is read to two decimal places
+// (basis points), e.g. 5, 18 or 12.5.
+func (m Money) Percent(percent float64) Money {
+	basisPoints := big.NewInt(int64(math.Round(percent * 100)))
+	product := new(big.Int).Mul(big.NewInt(m.minor), basisPoints)
+	m.minor = roundQuotient(product, big.NewInt(10000))
+	return m
+}
+
+// Div returns the amount split n ways, rounded half away from zero, such as
+// a package total per traveller.
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return m
+	}
+	m.minor = roundQuotient(big.NewInt(m.minor), big.NewInt(n))
+	return m
+}
+
+// Ratio returns the amount times numerator/denominator, rounded half away
+// from zero, such as the pre-tax part of a tax-inclusive price.
+func (m Money) Ratio(numerator, denominator int64) Money {
+	if denominator == 0 {
+		return m
+	}
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(numerator))
+	m.minor = roundQuotient(product, big.NewInt(denominator))
+	return m
+}
+
+func roundQuotient(numerator, denominator *big.Int) int64 {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+// Cmp compares two amounts exactly, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) int {
+	a, b := align(m, other)
+	switch {
+	case a.minor < b.minor:
+		return -1
+	case a.minor > b.minor:
+		return 1
+	}
+	return 0
+}
+
+func align(a, b Money) (Money, Money) {
+	for a.scale < b.scale {
+		a.minor *= 10
+		a.scale++
+	}
+	for b.scale < a.scale {
+		b.minor *= 10
+		b.scale++
+	}
+	return a, b
+}
+
+// MarshalJSON writes the structured form, {"amount":"2890000.00","currency":"INR"},
+// with the amount as a string so no precision is lost.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.IsSet() {
+		return []byte("null"), nil
+	}
+	amount, _ := json.Marshal(m.String())
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.currency})
+}
+
+// UnmarshalJSON accepts the structured form, either with "amount" or with
+// "minor" units and a "currency", as well as the legacy plain strings
+// ("2,890,000.00") and numbers (18000.0). Numbers are read from their JSON
+// text, never through float64. Input that is not an amount is kept rather
+// than failing the whole request, so validation can report it by field.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	*m = Money{}
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		var structured moneyJSON
+		if err := json.Unmarshal(data, &structured); err != nil {
+			return err
+		}
+		if err := m.UnmarshalJSON(structured.Amount); err != nil {
+			return err
+		}
+		if structured.Minor != nil {
+			money, err := NewMoney(*structured.Minor, structured.Currency)
+			if err != nil || len(structured.Amount) > 0 {
+				*m = Money{raw: string(data)}
+				return nil
+			}
+			*m = money
+			return nil
+		}
+		if structured.Currency != "" && m.Valid() {
+			*m = m.WithCurrency(structured.Currency)
+		}
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		m.parse(text)
+		return nil
+	case len(data) == 0:
+		return nil
+	default:
+		m.parse(string(data))
+		return nil
+	}
+}
+
+func (m *Money) parse(text string) {
+	money, err := ParseMoney(text)
+	if err != nil {
+		*m = Money{raw: text}
+		return
+	}
+	*m = money
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func mustMoney(t *testing.T, s string) Money {
+	t.Helper()
+	m, err := ParseMoney(s)
+	if err != nil {
+		t.Fatalf("ParseMoney(%q) error = %v", s, err)
+	}
+	return m
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantMinor int64
+		wantScale int
+		wantErr   bool
+	}{
+		{"1500", 1500, 0, false},
+		{"2,890,000.00", 289000000, 2, false},
+		{" 1 500.5 ", 15005, 1, false},
+		{"-250.50", -25050, 2, false},
+		{"0.001", 1, 3, false},
+		{"999999999999999", 999999999999999, 0, false},
+		{"1000000000000000", 0, 0, true},
+		{"₹1500", 0, 0, true},
+		{"1e3", 0, 0, true},
+		{"1.", 0, 0, true},
+		{".5", 0, 0, true},
+		{"", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseMoney(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Errorf("ParseMoney(%q) error = %v, want %v", tt.input, err, ErrInvalidAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error = %v", tt.input, err)
+			}
+			if m.Minor() != tt.wantMinor || m.Scale() != tt.wantScale {
+				t.Errorf("ParseMoney(%q) = %d at scale %d, want %d at scale %d", tt.input, m.Minor(), m.Scale(), tt.wantMinor, tt.wantScale)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{mustMoney(t, "2890000.00"), "2890000.00"},
+		{mustMoney(t, "-0.05"), "-0.05"},
+		{mustMoney(t, "0.5"), "0.5"},
+		{mustMoney(t, "42"), "42"},
+		{Money{}, ""},
+		{Money{raw: "lots"}, "lots"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestMoneyRescale(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{"10", 2, "10.00", false},
+		{"10.50", 0, "", true},
+		{"10.500", 2, "10.50", false},
+		{"10.005", 2, "", true},
+		{"1500.00", 0, "1500", false},
+	}
+
+	for _, tt := range tests {
+		got, err := mustMoney(t, tt.input).Rescale(tt.decimals)
+		if tt.wantErr {
+			if !errors.Is(err, ErrMoneyPrecision) {
+				t.Errorf("Rescale(%s, %d) error = %v, want %v", tt.input, tt.decimals, err, ErrMoneyPrecision)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("Rescale(%s, %d) = %s, %v, want %s", tt.input, tt.decimals, got, err, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want string
+	}{
+		{"add aligns scales", mustMoney(t, "0.1").Add(mustMoney(t, "0.20")), "0.30"},
+		{"sum without float error", mustMoney(t, "0.1").Add(mustMoney(t, "0.2")), "0.3"},
+		{"sub", mustMoney(t, "100").Sub(mustMoney(t, "0.01")), "99.99"},
+		{"mul", mustMoney(t, "4500.50").Mul(3), "13501.50"},
+		{"percent", mustMoney(t, "1000.00").Percent(5), "50.00"},
+		{"percent rounds half up", mustMoney(t, "0.10").Percent(5), "0.01"},
+		{"percent fraction", mustMoney(t, "200.00").Percent(12.5), "25.00"},
+		{"percent negative rounds away from zero", mustMoney(t, "-0.10").Percent(5), "-0.01"},
+		{"div rounds half up", mustMoney(t, "100.00").Div(3), "33.33"},
+		{"div by zero is a no-op", mustMoney(t, "100.00").Div(0), "100.00"},
+		{"ratio", mustMoney(t, "105.00").Ratio(100, 105), "100.00"},
+		{"ratio rounds", mustMoney(t, "0.05").Ratio(1, 2), "0.03"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10", "10.00", 0},
+		{"10.01", "10", 1},
+		{"-5", "0.5", -1},
+	}
+
+	for _, tt := range tests {
+		if got := mustMoney(t, tt.a).Cmp(mustMoney(t, tt.b)); got != tt.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		currency string
+		want     string
+		wantErr  bool
+	}{
+		{123456, "inr", "1234.56", false},
+		{1500, "JPY", "1500", false},
+		{1500, "KWD", "1.500", false},
+		{1, "XXX1", "", true},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMoney(tt.minor, tt.currency)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewMoney(%d, %q) succeeded", tt.minor, tt.currency)
+			}
+			continue
+		}
+		if err != nil || m.String() != tt.want {
+			t.Errorf("NewMoney(%d, %q) = %s, %v, want %s", tt.minor, tt.currency, m, err, tt.want)
+		}
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		want         string
+		wantCurrency string
+		wantValid    bool
+		wantSet      bool
+	}{
+		{"number", `18000.50`, "18000.50", "", true, true},
+		{"large number keeps precision", `123456789012.35`, "123456789012.35", "", true, true},
+		{"grouped string", `"2,890,000.00"`, "2890000.00", "", true, true},
+		{"structured amount", `{"amount":"1500.00","currency":"usd"}`, "1500.00", "USD", true, true},
+		{"structured minor units", `{"minor":150000,"currency":"INR"}`, "1500.00", "INR", true, true},
+		{"minor units need a known currency", `{"minor":1,"currency":"ZZZ"}`, `{"minor":1,"currency":"ZZZ"}`, "", false, true},
+		{"minor and amount together", `{"amount":"1","minor":100,"currency":"INR"}`, `{"amount":"1","minor":100,"currency":"INR"}`, "", false, true},
+		{"unreadable text is kept", `"about 5k"`, "about 5k", "", false, true},
+		{"null", `null`, "", "", true, false},
+		{"empty string", `""`, "", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			if err := json.Unmarshal([]byte(tt.input), &m); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.input, err)
+			}
+			if m.String() != tt.want || m.Currency() != tt.wantCurrency || m.Valid() != tt.wantValid || m.IsSet() != tt.wantSet {
+				t.Errorf("Unmarshal(%s) = %q %q valid=%v set=%v, want %q %q valid=%v set=%v",
+					tt.input, m.String(), m.Currency(), m.Valid(), m.IsSet(), tt.want, tt.wantCurrency, tt.wantValid, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{mustMoney(t, "2890000.00").WithCurrency("inr"), `{"amount":"2890000.00","currency":"INR"}`},
+		{mustMoney(t, "-1.5"), `{"amount":"-1.5"}`},
+		{Money{}, `null`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.money)
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Marshal(%v) = %s, %v, want %s", tt.money, data, err, tt.want)
+			continue
+		}
+
+		var back Money
+		if err := json.Unmarshal(data, &back); err != nil || back.Cmp(tt.money) != 0 || back.Currency() != tt.money.Currency() {
+			t.Errorf("round trip of %s = %v (%v)", data, back, err)
+		}
+	}
+}
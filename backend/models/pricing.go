@@ -0,0 +1,88 @@
+package models
+
+// Price bases: a price per traveller, or one price for the whole group (per
+// room for hotels, per vehicle for transfers with a capacity).
+const (
+	PricePerPax   = "per_pax"
+	PricePerGroup = "per_group"
+)
+
+// Pricing modes: reconcile checks payment.totalAmount against the computed
+// total; override replaces it.
+const (
+	PricingReconcile = "reconcile"
+	PricingOverride  = "override"
+)
+
+// PricingOptions represents a request to compute the package price from its
+// line items
+type PricingOptions struct {
+	Mode            string      `json:"mode" validate:"omitempty,oneof=reconcile override"`
+	Basis           PriceBasis  `json:"basis"`
+	MarkupPercent   *float64    `json:"markupPercent" validate:"omitempty,min=0,max=1000"`
+	DiscountPercent float64     `json:"discountPercent" validate:"min=0,max=100"`
+	Discount        Money       `json:"discount" validate:"omitempty,money"`
+	GSTPercent      *float64    `json:"gstPercent" validate:"omitempty,min=0,max=100"`
+	Overseas        bool        `json:"overseas"`
+	Items           []PriceItem `json:"items" validate:"dive"`
+}
+
+// PriceBasis represents whether each kind of line item is priced per
+// traveller or per group
+type PriceBasis struct {
+	Flights    string `json:"flights" validate:"omitempty,oneof=per_pax per_group"`
+	Hotels     string `json:"hotels" validate:"omitempty,oneof=per_pax per_group"`
+	Activities string `json:"activities" validate:"omitempty,oneof=per_pax per_group"`
+	Transfers  string `json:"transfers" validate:"omitempty,oneof=per_pax per_group"`
+}
+
+// PriceItem represents a charge outside the itinerary, such as visa fees or
+// travel insurance
+type PriceItem struct {
+	Description string `json:"description" validate:"required"`
+	Price       Money  `json:"price" validate:"required,money"`
+	Basis       string `json:"basis" validate:"omitempty,oneof=per_pax per_group"`
+	Quantity    int    `json:"quantity" validate:"omitempty,min=1"`
+}
+
+// CostLine represents one priced line item, with any markup included in its
+// unit price
+type CostLine struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Basis       string `json:"basis"`
+	UnitPrice   Money  `json:"unitPrice"`
+	Quantity    int    `json:"quantity"`
+	Amount      Money  `json:"amount"`
+}
+
+// CostCategory represents the total of one kind of line item
+type CostCategory struct {
+	Name   string `json:"name"`
+	Items  int    `json:"items"`
+	Amount Money  `json:"amount"`
+}
+
+// CostBreakdown represents the package price computed from its line items.
+// Cost and Markup are for the agent; documents show only marked-up prices.
+type CostBreakdown struct {
+	Mode          string         `json:"mode"`
+	Currency      string         `json:"currency"`
+	Travelers     int            `json:"travelers"`
+	Lines         []CostLine     `json:"lines"`
+	Categories    []CostCategory `json:"categories"`
+	Cost          Money          `json:"cost"`
+	MarkupPercent float64        `json:"markupPercent"`
+	Markup        Money          `json:"markup"`
+	Subtotal      Money          `json:"subtotal"`
+	Discount      Money          `json:"discount"`
+	Taxable       Money          `json:"taxable"`
+	GSTPercent    float64        `json:"gstPercent"`
+	GST           Money          `json:"gst"`
+	Total         Money          `json:"total"`
+	PerPax        Money          `json:"perPax"`
+	TCS           Money          `json:"tcs"`
+	Payable       Money          `json:"payable"`
+	SuppliedTotal Money          `json:"suppliedTotal,omitzero"`
+	SuppliedTCS   Money          `json:"suppliedTcs,omitzero"`
+}
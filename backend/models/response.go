@@ -6,10 +6,11 @@ import (
 
 // APIResponse represents a standard API response
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Errors  []APIError  `json:"errors,omitempty"`
+	Success  bool        `json:"success"`
+	Message  string      `json:"message"`
+	Data     interface{} `json:"data,omitempty"`
+	Errors   []APIError  `json:"errors,omitempty"`
+	Warnings []APIError  `json:"warnings,omitempty"`
 }
 
 // APIError represents an API error
@@ -25,19 +26,135 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-// PDFResponse represents the response after generating a PDF
+// PDFResponse represents the response after generating a PDF. StorageKey is
+// kept server-side only; clients address the file by ID.
 type PDFResponse struct {
-	FilePath    string    `json:"file_path"`
-	FileName    string    `json:"file_name"`
-	FileSize    string    `json:"file_size"`
-	GeneratedAt time.Time `json:"generated_at"`
+	ID             string      `json:"id"`
+	StorageKey     string      `json:"-"`
+	FileName       string      `json:"file_name"`
+	ContentHash    string      `json:"content_hash"`
+	Version        int         `json:"version"`
+	FileSize       string      `json:"file_size"`
+	GeneratedAt    time.Time   `json:"generated_at"`
+	CoverThumbnail *PageImage  `json:"cover_thumbnail,omitempty"`
+	Pages          []PageImage `json:"pages,omitempty"`
+	InvoiceNumber  string      `json:"invoice_number,omitempty"`
+	Warnings       []APIError  `json:"warnings,omitempty"`
 }
 
-// FileInfoResponse represents file information response
+// PageImage represents a rendered image of one page of a stored PDF
+type PageImage struct {
+	Page   int    `json:"page"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Format string `json:"format"`
+	DPI    int    `json:"dpi"`
+	Size   int64  `json:"size"`
+}
+
+// PageImagesResponse represents the page images of a stored PDF
+type PageImagesResponse struct {
+	PDFID  string      `json:"pdf_id"`
+	Format string      `json:"format"`
+	DPI    int         `json:"dpi"`
+	Pages  []PageImage `json:"pages"`
+}
+
+// FileInfoResponse represents file information response. It is also the
+// metadata stored next to every generated PDF.
 type FileInfoResponse struct {
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	ModTime     time.Time `json:"mod_time"`
-	ContentType string    `json:"content_type"`
-	Extension   string    `json:"extension"`
+	ID             string     `json:"id"`
+	FileName       string     `json:"file_name"`
+	BaseName       string     `json:"base_name,omitempty"`
+	ContentHash    string     `json:"content_hash,omitempty"`
+	Version        int        `json:"version,omitempty"`
+	CoverThumbnail *PageImage `json:"cover_thumbnail,omitempty"`
+	FileSize       int64      `json:"file_size"`
+	ModTime        time.Time  `json:"mod_time"`
+	ContentType    string     `json:"content_type"`
+	Extension      string     `json:"extension"`
+	CustomerName   string     `json:"customer_name,omitempty"`
+	CustomerEmail  string     `json:"customer_email,omitempty"`
+	Destination    string     `json:"destination,omitempty"`
+	StartDate      string     `json:"start_date,omitempty"`
+	EndDate        string     `json:"end_date,omitempty"`
+	Travelers      int        `json:"travelers,omitempty"`
+	DocumentType   string     `json:"document_type,omitempty"`
+	InvoiceNumber  string     `json:"invoice_number,omitempty"`
+}
+
+// PDFListFilter represents the query options for listing stored PDFs
+type PDFListFilter struct {
+	Customer    string
+	Destination string
+	From        time.Time
+	To          time.Time
+	Page        int
+	PageSize    int
+}
+
+// PDFListResponse represents a page of stored PDFs
+type PDFListResponse struct {
+	Items    []FileInfoResponse `json:"items"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Total    int                `json:"total"`
+}
+
+// BrowserPoolStats represents the state of the managed Chrome browser pool
+type BrowserPoolStats struct {
+	Size             int   `json:"size"`
+	Running          int   `json:"running"`
+	Available        int   `json:"available"`
+	InUse            int   `json:"in_use"`
+	TabsServed       int64 `json:"tabs_served"`
+	BrowsersLaunched int64 `json:"browsers_launched"`
+	BrowsersRecycled int64 `json:"browsers_recycled"`
+	LaunchFailures   int64 `json:"launch_failures"`
+	Crashes          int64 `json:"crashes"`
+}
+
+// RenderQueueStats represents the state of the bounded render queue
+type RenderQueueStats struct {
+	Workers   int   `json:"workers"`
+	Capacity  int   `json:"capacity"`
+	Pending   int   `json:"pending"`
+	Active    int   `json:"active"`
+	Completed int64 `json:"completed"`
+	Rejected  int64 `json:"rejected"`
+}
+
+// Job statuses reported by the asynchronous generation API
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRendering = "rendering"
+	JobStatusDone      = "done"
+	JobStatusFailed    = "failed"
+)
+
+// PDFJob represents an asynchronous PDF generation job
+type PDFJob struct {
+	ID          string       `json:"id"`
+	Status      string       `json:"status"`
+	SubmittedAt time.Time    `json:"submitted_at"`
+	StartedAt   *time.Time   `json:"started_at,omitempty"`
+	FinishedAt  *time.Time   `json:"finished_at,omitempty"`
+	QueuedMs    int64        `json:"queued_ms"`
+	RenderMs    int64        `json:"render_ms"`
+	Error       string       `json:"error,omitempty"`
+	Result      *PDFResponse `json:"result,omitempty"`
+}
+
+// CleanupStats represents what the retention janitor has removed
+type CleanupStats struct {
+	Enabled      bool       `json:"enabled"`
+	DryRun       bool       `json:"dry_run"`
+	Runs         int64      `json:"runs"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	FilesRemoved int64      `json:"files_removed"`
+	BytesRemoved int64      `json:"bytes_removed"`
+	LastRunFiles int        `json:"last_run_files"`
+	LastRunBytes int64      `json:"last_run_bytes"`
+	StoredFiles  int        `json:"stored_files"`
+	StoredBytes  int64      `json:"stored_bytes"`
 }
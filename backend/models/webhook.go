@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Webhook events sent when a PDF job finishes
+const (
+	WebhookEventCompleted = "pdf.completed"
+	WebhookEventFailed    = "pdf.failed"
+)
+
+// WebhookPayload represents the signed JSON body posted to a callback URL
+type WebhookPayload struct {
+	Event       string       `json:"event"`
+	JobID       string       `json:"job_id"`
+	Status      string       `json:"status"`
+	Error       string       `json:"error,omitempty"`
+	SubmittedAt time.Time    `json:"submitted_at"`
+	FinishedAt  *time.Time   `json:"finished_at,omitempty"`
+	PDF         *PDFResponse `json:"pdf,omitempty"`
+}
+
+// WebhookDelivery represents the delivery log of one callback
+type WebhookDelivery struct {
+	ID          string           `json:"id"`
+	JobID       string           `json:"job_id"`
+	Event       string           `json:"event"`
+	URL         string           `json:"url"`
+	Status      string           `json:"status"`
+	Attempts    []WebhookAttempt `json:"attempts"`
+	CreatedAt   time.Time        `json:"created_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+}
+
+// WebhookAttempt represents a single HTTP attempt of a delivery
+type WebhookAttempt struct {
+	Number      int       `json:"number"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/chromedp/chromedp"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrBrowserPoolClosed = errors.New("browser pool is closed")
+
+// BrowserPool keeps a fixed number of warm Chrome processes and hands out
+// isolated tabs on them, so a render does not pay for a browser cold start.
+type BrowserPool struct {
+	cfg   config.BrowserPoolConfig
+	slots chan *pooledBrowser
+
+	// startBrowser and openTab drive Chrome; tests replace them.
+	startBrowser browserLauncher
+	openTab      tabOpener
+
+	mu     sync.Mutex
+	closed bool
+	stats  models.BrowserPoolStats
+}
+
+// browserLauncher starts a browser process. The returned context lives as
+// long as the browser; cancel stops it.
+type browserLauncher func() (context.Context, context.CancelFunc, error)
+
+// tabOpener opens an isolated tab on a running browser.
+type tabOpener func(browser context.Context) (context.Context, context.CancelFunc)
+
+type pooledBrowser struct {
+	id         int
+	ctx        context.Context
+	cancel     context.CancelFunc
+	launchedAt time.Time
+	tabsServed int
+}
+
+// BrowserTab is a single tab leased from the pool. Release must be called
+// exactly once when the caller is done with it.
+type BrowserTab struct {
+	Ctx     context.Context
+	pool    *BrowserPool
+	browser *pooledBrowser
+	cancel  context.CancelFunc
+}
+
+func NewBrowserPool() *BrowserPool {
+	return newBrowserPool(config.AppConfig.ChromeDP.Pool, launchChrome, openChromeTab)
+}
+
+func newBrowserPool(cfg config.BrowserPoolConfig, startBrowser browserLauncher, openTab tabOpener) *BrowserPool {
+	if cfg.Size < 1 {
+		cfg.Size = 1
+	}
+
+	pool := &BrowserPool{
+		cfg:          cfg,
+		slots:        make(chan *pooledBrowser, cfg.Size),
+		startBrowser: startBrowser,
+		openTab:      openTab,
+	}
+	pool.stats.Size = cfg.Size
+
+	for i := 0; i < cfg.Size; i++ {
+		go pool.warm(&pooledBrowser{id: i + 1})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"size":              cfg.Size,
+		"maxTabsPerBrowser": cfg.MaxTabsPerBrowser,
+		"maxBrowserAge":     cfg.MaxBrowserAge,
+	}).Info("Browser pool started")
+
+	return pool
+}
+
+func chromeAllocatorOptions() []chromedp.ExecAllocatorOption {
+	return append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.DisableGPU,
+		chromedp.NoDefaultBrowserCheck,
+		chromedp.Flag("headless", config.AppConfig.ChromeDP.Headless),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-web-security", config.AppConfig.ChromeDP.DisableWebSecurity),
+		chromedp.Flag("disable-features", "VizDisplayCompositor"),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("disable-extensions", true),
+		chromedp.Flag("disable-plugins", true),
+		chromedp.Flag("disable-background-timer-throttling", true),
+		chromedp.Flag("disable-backgrounding-occluded-windows", true),
+		chromedp.Flag("disable-renderer-backgrounding", true),
+		chromedp.Flag("force-color-profile", "srgb"),
+		chromedp.Flag("enable-print-background", true),
+	)
+}
+
+// warm launches the browser for a slot and then makes the slot available.
+// A failed launch still returns the slot; Acquire retries the launch lazily.
+func (p *BrowserPool) warm(b *pooledBrowser) {
+	if err := p.launch(b); err != nil {
+		logrus.WithError(err).WithField("browserID", b.id).Warn("Failed to warm browser, will retry on demand")
+	}
+	p.slots <- b
+}
+
+// launchChrome starts a headless Chrome process.
+func launchChrome() (context.Context, context.CancelFunc, error) {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), chromeAllocatorOptions()...)
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+
+	// The first Run allocates the browser process. It must not carry a
+	// deadline, otherwise the whole browser dies when the deadline passes.
+	if err := chromedp.Run(browserCtx); err != nil {
+		browserCancel()
+		allocCancel()
+		return nil, nil, err
+	}
+
+	return browserCtx, func() {
+		browserCancel()
+		allocCancel()
+	}, nil
+}
+
+// openChromeTab opens a tab in a fresh browser context, so cookies and
+// storage never leak between requests.
+func openChromeTab(browser context.Context) (context.Context, context.CancelFunc) {
+	return chromedp.NewContext(browser, chromedp.WithNewBrowserContext())
+}
+
+func (p *BrowserPool) launch(b *pooledBrowser) error {
+	browserCtx, cancel, err := p.startBrowser()
+	if err != nil {
+		p.mu.Lock()
+		p.stats.LaunchFailures++
+		p.mu.Unlock()
+		return fmt.Errorf("failed to launch browser: %w", err)
+	}
+
+	b.ctx = browserCtx
+	b.cancel = cancel
+	b.launchedAt = time.Now()
+	b.tabsServed = 0
+
+	p.mu.Lock()
+	p.stats.BrowsersLaunched++
+	p.stats.Running++
+	p.mu.Unlock()
+
+	logrus.WithField("browserID", b.id).Info("Browser launched")
+	return nil
+}
+
+func (p *BrowserPool) shutdown(b *pooledBrowser) {
+	if b.cancel == nil {
+		return
+	}
+	b.cancel()
+	b.ctx = nil
+	b.cancel = nil
+
+	p.mu.Lock()
+	p.stats.Running--
+	p.mu.Unlock()
+}
+
+func (b *pooledBrowser) alive() bool {
+	return b.ctx != nil && b.ctx.Err() == nil
+}
+
+// Acquire waits for a free browser and opens a new tab on it in a fresh
+// browser context, so cookies and storage never leak between requests.
+func (p *BrowserPool) Acquire(ctx context.Context) (*BrowserTab, error) {
+	if p.isClosed() {
+		return nil, ErrBrowserPoolClosed
+	}
+
+	if p.cfg.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.AcquireTimeout)
+		defer cancel()
+	}
+
+	var b *pooledBrowser
+	select {
+	case b = <-p.slots:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for a browser: %w", ctx.Err())
+	}
+
+	if p.isClosed() {
+		p.slots <- b
+		return nil, ErrBrowserPoolClosed
+	}
+
+	if b.ctx != nil && !b.alive() {
+		logrus.WithField("browserID", b.id).Warn("Browser found dead, relaunching")
+		p.mu.Lock()
+		p.stats.Crashes++
+		p.mu.Unlock()
+		p.shutdown(b)
+	}
+	if !b.alive() {
+		if err := p.launch(b); err != nil {
+			p.slots <- b
+			return nil, err
+		}
+	}
+
+	tabCtx, tabCancel := p.openTab(b.ctx)
+
+	p.mu.Lock()
+	p.stats.InUse++
+	p.mu.Unlock()
+
+	return &BrowserTab{
+		Ctx:     tabCtx,
+		pool:    p,
+		browser: b,
+		cancel:  tabCancel,
+	}, nil
+}
+
+// Release closes the tab and returns its browser to the pool. Browsers that
+// crashed, served too many tabs or grew too old are recycled in the
+// background so the slot comes back warm.
+func (t *BrowserTab) Release() {
+	p := t.pool
+	b := t.browser
+
+	t.cancel()
+	b.tabsServed++
+
+	p.mu.Lock()
+	p.stats.InUse--
+	p.stats.TabsServed++
+	p.mu.Unlock()
+
+	reason := ""
+	switch {
+	case !b.alive():
+		reason = "crashed"
+		p.mu.Lock()
+		p.stats.Crashes++
+		p.mu.Unlock()
+	case p.cfg.MaxTabsPerBrowser > 0 && b.tabsServed >= p.cfg.MaxTabsPerBrowser:
+		reason = "max tabs reached"
+	case p.cfg.MaxBrowserAge > 0 && time.Since(b.launchedAt) >= p.cfg.MaxBrowserAge:
+		reason = "max age reached"
+	}
+
+	if reason == "" || p.isClosed() {
+		p.slots <- b
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"browserID":  b.id,
+		"reason":     reason,
+		"tabsServed": b.tabsServed,
+		"age":        time.Since(b.launchedAt).String(),
+	}).Info("Recycling browser")
+
+	p.shutdown(b)
+	p.mu.Lock()
+	p.stats.BrowsersRecycled++
+	p.mu.Unlock()
+
+	go p.warm(b)
+}
+
+func (p *BrowserPool) Stats() models.BrowserPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Available = len(p.slots)
+	return stats
+}
+
+func (p *BrowserPool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// Close waits for leased tabs to be released and shuts every browser down.
+func (p *BrowserPool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	for i := 0; i < p.cfg.Size; i++ {
+		select {
+		case b := <-p.slots:
+			p.shutdown(b)
+		case <-ctx.Done():
+			return fmt.Errorf("browser pool did not drain: %w", ctx.Err())
+		}
+	}
+
+	logrus.Info("Browser pool closed")
+	return nil
+}
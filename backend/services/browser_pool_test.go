@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+// fakeLauncher stands in for Chrome: every browser is a cancellable context.
+type fakeLauncher struct {
+	mu       sync.Mutex
+	fail     int
+	browsers []context.CancelFunc
+}
+
+func (f *fakeLauncher) start() (context.Context, context.CancelFunc, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fail > 0 {
+		f.fail--
+		return nil, nil, errors.New("chrome not found")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f.browsers = append(f.browsers, cancel)
+	return ctx, cancel, nil
+}
+
+// crashAll kills every browser launched so far, as if Chrome died.
+func (f *fakeLauncher) crashAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, cancel := range f.browsers {
+		cancel()
+	}
+}
+
+func fakeOpenTab(browser context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(browser)
+}
+
+func newTestBrowserPool(t *testing.T, cfg config.BrowserPoolConfig, launcher *fakeLauncher) *BrowserPool {
+	t.Helper()
+
+	p := newBrowserPool(cfg, launcher.start, fakeOpenTab)
+	t.Cleanup(func() { p.Close(context.Background()) })
+	waitForStats(t, p, func(s models.BrowserPoolStats) bool { return s.Available == cfg.Size })
+	return p
+}
+
+// waitForStats polls until the background warm-up settles into cond.
+func waitForStats(t *testing.T, p *BrowserPool, cond func(models.BrowserPoolStats) bool) models.BrowserPoolStats {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s := p.Stats()
+		if cond(s) {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool stats never settled: %+v", s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func acquireTab(t *testing.T, p *BrowserPool) *BrowserTab {
+	t.Helper()
+
+	tab, err := p.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	return tab
+}
+
+func TestBrowserPoolAcquireTimeout(t *testing.T) {
+	p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 1, AcquireTimeout: 20 * time.Millisecond}, &fakeLauncher{})
+
+	tab := acquireTab(t, p)
+	defer tab.Release()
+
+	if _, err := p.Acquire(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if s := p.Stats(); s.InUse != 1 || s.Available != 0 {
+		t.Errorf("stats = %+v, want 1 in use and none available", s)
+	}
+}
+
+func TestBrowserPoolRecycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.BrowserPoolConfig
+		tabs  int64
+		wantR int64
+	}{
+		{"below tab limit", config.BrowserPoolConfig{Size: 1, MaxTabsPerBrowser: 3}, 2, 0},
+		{"tab limit reached", config.BrowserPoolConfig{Size: 1, MaxTabsPerBrowser: 2}, 4, 2},
+		{"max age reached", config.BrowserPoolConfig{Size: 1, MaxBrowserAge: time.Nanosecond}, 3, 3},
+		{"no limits", config.BrowserPoolConfig{Size: 1}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestBrowserPool(t, tt.cfg, &fakeLauncher{})
+
+			for i := int64(0); i < tt.tabs; i++ {
+				acquireTab(t, p).Release()
+				waitForStats(t, p, func(s models.BrowserPoolStats) bool { return s.Available == 1 })
+			}
+
+			s := p.Stats()
+			if s.BrowsersRecycled != tt.wantR {
+				t.Errorf("BrowsersRecycled = %d, want %d", s.BrowsersRecycled, tt.wantR)
+			}
+			if s.BrowsersLaunched != 1+tt.wantR {
+				t.Errorf("BrowsersLaunched = %d, want %d", s.BrowsersLaunched, 1+tt.wantR)
+			}
+			if s.Running != 1 || s.TabsServed != tt.tabs || s.InUse != 0 {
+				t.Errorf("stats = %+v, want 1 running, %d tabs served, none in use", s, tt.tabs)
+			}
+		})
+	}
+}
+
+func TestBrowserPoolRelaunchesCrashedBrowser(t *testing.T) {
+	t.Run("crashed while idle", func(t *testing.T) {
+		launcher := &fakeLauncher{}
+		p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 1}, launcher)
+
+		launcher.crashAll()
+		tab := acquireTab(t, p)
+		if tab.Ctx.Err() != nil {
+			t.Fatal("tab opened on a dead browser")
+		}
+		tab.Release()
+
+		s := p.Stats()
+		if s.Crashes != 1 || s.BrowsersLaunched != 2 || s.Running != 1 {
+			t.Errorf("stats = %+v, want 1 crash, 2 launches, 1 running", s)
+		}
+	})
+
+	t.Run("crashed while leased", func(t *testing.T) {
+		launcher := &fakeLauncher{}
+		p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 1}, launcher)
+
+		tab := acquireTab(t, p)
+		launcher.crashAll()
+		tab.Release()
+
+		s := waitForStats(t, p, func(s models.BrowserPoolStats) bool { return s.Available == 1 })
+		if s.Crashes != 1 || s.BrowsersRecycled != 1 || s.BrowsersLaunched != 2 || s.Running != 1 {
+			t.Errorf("stats = %+v, want 1 crash, 1 recycle, 2 launches, 1 running", s)
+		}
+	})
+
+	t.Run("launch failure retried on acquire", func(t *testing.T) {
+		p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 1}, &fakeLauncher{fail: 1})
+
+		acquireTab(t, p).Release()
+
+		s := p.Stats()
+		if s.LaunchFailures != 1 || s.BrowsersLaunched != 1 || s.Running != 1 {
+			t.Errorf("stats = %+v, want 1 launch failure, 1 launch, 1 running", s)
+		}
+	})
+}
+
+func TestBrowserPoolClose(t *testing.T) {
+	t.Run("waits for leased tabs", func(t *testing.T) {
+		launcher := &fakeLauncher{}
+		p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 2}, launcher)
+
+		tab := acquireTab(t, p)
+		closed := make(chan error, 1)
+		go func() { closed <- p.Close(context.Background()) }()
+
+		select {
+		case err := <-closed:
+			t.Fatalf("Close() returned %v while a tab was leased", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		tab.Release()
+		if err := <-closed; err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if s := p.Stats(); s.Running != 0 {
+			t.Errorf("Running = %d after Close, want 0", s.Running)
+		}
+		if _, err := p.Acquire(context.Background()); !errors.Is(err, ErrBrowserPoolClosed) {
+			t.Errorf("Acquire() after Close error = %v, want %v", err, ErrBrowserPoolClosed)
+		}
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		p := newTestBrowserPool(t, config.BrowserPoolConfig{Size: 1}, &fakeLauncher{})
+
+		tab := acquireTab(t, p)
+		defer tab.Release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := p.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// CleanupService periodically removes PDFs older than pdf.max_file_age and
+// evicts the oldest files while storage exceeds pdf.cleanup.max_storage.
+// Records of expired download links are pruned on the same schedule. A
+// document's size includes its metadata and the artifacts under "<id>/", and
+// artifacts left behind by a document that no longer exists are removed.
+type CleanupService struct {
+	ctx         context.Context
+	fileService *FileService
+	linkService *LinkService
+	maxAge      time.Duration
+	maxStorage  int64
+	interval    time.Duration
+	dryRun      bool
+	stop        chan struct{}
+	stopOnce    sync.Once
+	done        chan struct{}
+
+	mu    sync.Mutex
+	stats models.CleanupStats
+}
+
+// NewCleanupService starts the cleanup loop. ctx is the server's lifetime;
+// cancelling it aborts a running pass.
+func NewCleanupService(ctx context.Context, fileService *FileService, linkService *LinkService) *CleanupService {
+	cfg := config.AppConfig.PDF
+
+	maxStorage, err := utils.ParseByteSize(cfg.Cleanup.MaxStorage)
+	if err != nil {
+		logrus.WithError(err).Warn("Ignoring invalid pdf.cleanup.max_storage")
+		maxStorage = 0
+	}
+
+	s := &CleanupService{
+		ctx:         ctx,
+		fileService: fileService,
+		linkService: linkService,
+		maxAge:      cfg.MaxFileAge,
+		maxStorage:  maxStorage,
+		interval:    cfg.Cleanup.Interval,
+		dryRun:      cfg.Cleanup.DryRun,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	s.stats.Enabled = cfg.Cleanup.Enabled && s.interval > 0
+	s.stats.DryRun = s.dryRun
+
+	if !s.stats.Enabled {
+		close(s.done)
+		logrus.Info("PDF cleanup disabled")
+		return s
+	}
+
+	go s.loop()
+
+	logrus.WithFields(logrus.Fields{
+		"interval":   s.interval.String(),
+		"maxAge":     s.maxAge.String(),
+		"maxStorage": maxStorage,
+		"dryRun":     s.dryRun,
+	}).Info("PDF cleanup started")
+
+	return s
+}
+
+func (s *CleanupService) loop() {
+	defer close(s.done)
+
+	s.Run(s.ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Run(s.ctx)
+		case <-s.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Run performs a single cleanup pass.
+func (s *CleanupService) Run(ctx context.Context) {
+	// Artifacts are listed first: they are written after their PDF, so any
+	// artifact seen here belongs to a PDF the scan below will find.
+	artifacts, err := s.fileService.artifactUsage(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("PDF cleanup failed to scan storage")
+		return
+	}
+	infos, err := s.fileService.scan(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("PDF cleanup failed to scan storage")
+		return
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime.Before(infos[j].ModTime)
+	})
+
+	// A document's footprint is its PDF plus its metadata and artifacts.
+	sizes := make(map[string]int64, len(infos))
+	var total int64
+	for _, info := range infos {
+		sizes[info.ID] = info.FileSize + artifacts[info.ID]
+		delete(artifacts, info.ID)
+		total += sizes[info.ID]
+	}
+	for _, size := range artifacts {
+		total += size
+	}
+
+	var removedFiles int
+	var removedBytes int64
+	remove := func(info models.FileInfoResponse, reason string) {
+		size := sizes[info.ID]
+		entry := logrus.WithFields(logrus.Fields{
+			"id":       info.ID,
+			"fileName": info.FileName,
+			"fileSize": size,
+			"modTime":  info.ModTime,
+			"reason":   reason,
+			"dryRun":   s.dryRun,
+		})
+		if !s.dryRun {
+			if err := s.fileService.DeletePDF(ctx, info.ID); err != nil {
+				entry.WithError(err).Error("PDF cleanup failed to remove file")
+				return
+			}
+		}
+		entry.Info("PDF cleanup removed file")
+		removedFiles++
+		removedBytes += size
+		total -= size
+	}
+
+	// Artifacts whose PDF is gone can never be served again.
+	for id, size := range artifacts {
+		entry := logrus.WithFields(logrus.Fields{
+			"id":       id,
+			"fileSize": size,
+			"reason":   "orphaned",
+			"dryRun":   s.dryRun,
+		})
+		if !s.dryRun {
+			if err := s.fileService.deleteOrphan(ctx, id); err != nil {
+				entry.WithError(err).Error("PDF cleanup failed to remove artifacts")
+				continue
+			}
+		}
+		entry.Info("PDF cleanup removed artifacts")
+		removedBytes += size
+		total -= size
+	}
+
+	cutoff := time.Now().Add(-s.maxAge)
+	kept := infos[:0]
+	for _, info := range infos {
+		if s.maxAge > 0 && info.ModTime.Before(cutoff) {
+			remove(info, "expired")
+			continue
+		}
+		kept = append(kept, info)
+	}
+
+	// kept is oldest first, so eviction removes the oldest files.
+	for _, info := range kept {
+		if s.maxStorage <= 0 || total <= s.maxStorage {
+			break
+		}
+		remove(info, "over quota")
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.stats.Runs++
+	s.stats.LastRunAt = &now
+	s.stats.LastRunFiles = removedFiles
+	s.stats.LastRunBytes = removedBytes
+	s.stats.FilesRemoved += int64(removedFiles)
+	s.stats.BytesRemoved += removedBytes
+	s.stats.StoredFiles = len(infos) - removedFiles
+	s.stats.StoredBytes = total
+	s.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"removedFiles": removedFiles,
+		"removedBytes": removedBytes,
+		"storedBytes":  total,
+		"dryRun":       s.dryRun,
+	}).Info("PDF cleanup finished")
+
+	if !s.dryRun {
+		pruned, err := s.linkService.PruneExpired(ctx)
+		if err != nil {
+			logrus.WithError(err).Error("PDF cleanup failed to prune download links")
+		} else if pruned > 0 {
+			logrus.WithField("prunedLinks", pruned).Info("PDF cleanup pruned expired download links")
+		}
+	}
+}
+
+func (s *CleanupService) Stats() models.CleanupStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Close stops the cleanup loop, waiting for a running pass to finish.
+func (s *CleanupService) Close(ctx context.Context) error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/google/uuid"
+)
+
+// seedDocument saves a PDF with a source and cover image next to it.
+func seedDocument(t *testing.T, fileService *FileService, name string, pdfSize, artifactSize int) string {
+	t.Helper()
+
+	ctx := context.Background()
+	info := &models.FileInfoResponse{CustomerName: name}
+	if _, err := fileService.SavePDF(ctx, bytes.Repeat([]byte("p"), pdfSize), name+".pdf", StorageModeVersion, info); err != nil {
+		t.Fatal(err)
+	}
+	for _, artifact := range []string{"source.json", "cover.png"} {
+		if err := fileService.storage.Put(ctx, fileService.artifactKey(info.ID, artifact), bytes.Repeat([]byte("a"), artifactSize/2), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Keeps modification times apart so eviction order is stable.
+	time.Sleep(2 * time.Millisecond)
+	return info.ID
+}
+
+// documentBytes sums the stored objects of PDF documents, leaving out
+// links, invoices and name indexes.
+func documentBytes(t *testing.T, storage Storage) int64 {
+	t.Helper()
+
+	objects, err := storage.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, linkKeyPrefix) || strings.HasPrefix(object.Key, invoiceKeyPrefix) || strings.HasPrefix(object.Key, nameIndexPrefix) {
+			continue
+		}
+		total += object.Size
+	}
+	return total
+}
+
+func hasObjects(t *testing.T, storage Storage, prefix string) bool {
+	t.Helper()
+
+	objects, err := storage.List(context.Background(), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(objects) > 0
+}
+
+func TestCleanupRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		dryRun      bool
+		orphan      bool
+		quota       func(total int64) int64
+		wantRemoved []bool
+	}{
+		{"under quota", false, false, func(total int64) int64 { return total }, []bool{false, false, false}},
+		// The PDFs alone fit, so only counting artifacts triggers eviction.
+		{"artifacts count", false, false, func(total int64) int64 { return total - 1 }, []bool{true, false, false}},
+		{"evicts oldest first", false, false, func(total int64) int64 { return total / 3 }, []bool{true, true, false}},
+		// The orphan alone overflows the quota.
+		{"orphaned artifacts", false, true, func(total int64) int64 { return total }, []bool{false, false, false}},
+		{"dry run", true, true, func(total int64) int64 { return 1 }, []bool{false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileService := &FileService{storage: NewMemoryStorage(), storageMode: StorageModeVersion}
+			ids := []string{
+				seedDocument(t, fileService, "first", 100, 1000),
+				seedDocument(t, fileService, "second", 100, 1000),
+				seedDocument(t, fileService, "third", 100, 1000),
+			}
+			total := documentBytes(t, fileService.storage)
+
+			orphan := uuid.NewString()
+			if tt.orphan {
+				fileService.storage.Put(context.Background(), fileService.artifactKey(orphan, "cover.png"), []byte("orphan"), "")
+				fileService.storage.Put(context.Background(), fileService.metadataKey(orphan), []byte("{}"), "")
+			}
+
+			s := &CleanupService{
+				fileService: fileService,
+				linkService: NewLinkService(fileService),
+				maxStorage:  tt.quota(total),
+				dryRun:      tt.dryRun,
+			}
+			s.Run(context.Background())
+
+			for i, id := range ids {
+				gone := !hasObjects(t, fileService.storage, fileService.storedName(id))
+				if gone != tt.wantRemoved[i] {
+					t.Errorf("document %d removed = %v, want %v", i, gone, tt.wantRemoved[i])
+				}
+				if artifacts := hasObjects(t, fileService.storage, id+"/"); artifacts == gone {
+					t.Errorf("document %d artifacts kept = %v with the PDF removed = %v", i, artifacts, gone)
+				}
+			}
+			if tt.orphan && hasObjects(t, fileService.storage, orphan) == !tt.dryRun {
+				t.Errorf("orphan kept = %v, want %v", !tt.dryRun, tt.dryRun)
+			}
+
+			stats := s.Stats()
+			stored := documentBytes(t, fileService.storage)
+			if !tt.dryRun {
+				if stats.StoredBytes != stored {
+					t.Errorf("StoredBytes = %d, storage holds %d", stats.StoredBytes, stored)
+				}
+				if stored > s.maxStorage && s.maxStorage > 0 {
+					t.Errorf("storage holds %d, quota is %d", stored, s.maxStorage)
+				}
+			}
+			wantRemoval := tt.dryRun || tt.orphan || tt.wantRemoved[0]
+			if (stats.LastRunBytes > 0) != wantRemoval {
+				t.Errorf("LastRunBytes = %d, want removals %v", stats.LastRunBytes, wantRemoval)
+			}
+		})
+	}
+}
+
+func TestCleanupStopsWithContext(t *testing.T) {
+	fileService := &FileService{storage: NewMemoryStorage(), storageMode: StorageModeVersion}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := NewCleanupService(ctx, fileService, NewLinkService(fileService))
+	if !s.Stats().Enabled {
+		t.Skip("cleanup disabled by default config")
+	}
+	cancel()
+
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cleanup loop still running after its context was cancelled")
+	}
+}
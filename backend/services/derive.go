@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+// derivedConflict is a provided value that disagreed with the one computed
+// from the trip and hotel dates, reported under the rule that covers it.
+type derivedConflict struct {
+	rule      string
+	violation ruleViolation
+}
+
+var (
+	durationDaysPattern   = regexp.MustCompile(`(?i)(\d+)\s*days?\b`)
+	durationNightsPattern = regexp.MustCompile(`(?i)(\d+)\s*nights?\b`)
+)
+
+// deriveFields returns a copy of request with values that follow from its
+// dates filled in: the trip duration, missing day dates and hotel nights.
+// The duration and day dates are only filled when missing. A provided
+// duration is kept as written, since agents often describe trips their own
+// way, and one that disagrees with the dates is returned as a conflict. A
+// provided date that disagrees with its day number is left for the
+// day_dates_match_numbers rule, since either may be wrong. Hotel nights are
+// always recomputed, and a provided count that disagrees is replaced and
+// returned as a conflict.
+// The request itself is not modified, so validating it again reports the
+// same conflicts.
+func deriveFields(request *models.ItineraryRequest) (*models.ItineraryRequest, []derivedConflict) {
+	derived := *request
+	derived.Itinerary.Days = append([]models.Day(nil), request.Itinerary.Days...)
+	derived.Hotels = append([]models.Hotel(nil), request.Hotels...)
+
+	var conflicts []derivedConflict
+
+	start, end, ok := tripRange(request)
+	if ok {
+		duration := utils.CalculateDuration(request.Trip.StartDate, request.Trip.EndDate)
+		provided := request.Trip.Duration
+		switch {
+		case provided == "":
+			derived.Trip.Duration = duration
+		case !sameDuration(provided, duration):
+			conflicts = append(conflicts, derivedConflict{"trip_duration", ruleViolation{"trip.duration", fmt.Sprintf(
+				"duration %q does not match the trip dates (%s to %s), which span %s", provided, formatRuleDate(start), formatRuleDate(end), duration)}})
+		}
+
+		for i, day := range derived.Itinerary.Days {
+			if day.Date == "" && day.DayNumber >= 1 {
+				derived.Itinerary.Days[i].Date = formatRuleDate(start.AddDate(0, 0, day.DayNumber-1))
+			}
+		}
+	}
+
+	for i, hotel := range derived.Hotels {
+		checkIn, err := utils.ParseDate(hotel.CheckIn)
+		if err != nil {
+			continue
+		}
+		checkOut, err := utils.ParseDate(hotel.CheckOut)
+		if err != nil || !checkOut.After(checkIn) {
+			continue
+		}
+
+		nights := nightsBetween(checkIn, checkOut)
+		if hotel.Nights != 0 && hotel.Nights != nights {
+			conflicts = append(conflicts, derivedConflict{"hotel_nights", ruleViolation{fmt.Sprintf("hotels[%d].nights", i), fmt.Sprintf(
+				"nights is %d but %s to %s is %d nights", hotel.Nights, formatRuleDate(checkIn), formatRuleDate(checkOut), nights)}})
+		}
+		derived.Hotels[i].Nights = nights
+	}
+
+	return &derived, conflicts
+}
+
+// sameDuration reports whether a hand-written duration such as
+// "10 days / 9 nights" states the same days and nights as computed.
+func sameDuration(provided, computed string) bool {
+	if provided == computed {
+		return true
+	}
+
+	count := func(pattern *regexp.Regexp, s string) (int, bool) {
+		match := pattern.FindStringSubmatch(s)
+		if match == nil {
+			return 0, false
+		}
+		n, err := strconv.Atoi(match[1])
+		return n, err == nil
+	}
+
+	providedDays, ok := count(durationDaysPattern, provided)
+	if !ok {
+		return false
+	}
+	computedDays, _ := count(durationDaysPattern, computed)
+	if providedDays != computedDays {
+		return false
+	}
+
+	// "1 Day" has no nights; a provided night count must still agree.
+	providedNights, hasNights := count(durationNightsPattern, provided)
+	computedNights, _ := count(durationNightsPattern, computed)
+	return !hasNights || providedNights == computedNights
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestDeriveFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		duration      string
+		days          []models.Day
+		hotels        []models.Hotel
+		wantDuration  string
+		wantDates     []string
+		wantNights    []int
+		wantConflicts []string
+	}{
+		{
+			name:         "missing values are filled",
+			days:         []models.Day{{DayNumber: 1}, {DayNumber: 3}},
+			hotels:       []models.Hotel{{CheckIn: "2024-12-15", CheckOut: "2024-12-18"}},
+			wantDuration: "8 Days 7 Nights",
+			wantDates:    []string{"2024-12-15", "2024-12-17"},
+			wantNights:   []int{3},
+		},
+		{
+			name:         "matching duration is kept as written",
+			duration:     "8 days / 7 nights",
+			wantDuration: "8 days / 7 nights",
+		},
+		{
+			name:          "conflicting duration is kept and reported",
+			duration:      "7 Days 6 Nights",
+			wantDuration:  "7 Days 6 Nights",
+			wantConflicts: []string{"trip_duration"},
+		},
+		{
+			name:      "provided day dates are never replaced",
+			days:      []models.Day{{DayNumber: 2, Date: "2024-12-20"}},
+			wantDates: []string{"2024-12-20"},
+		},
+		{
+			name:          "conflicting nights are replaced and reported",
+			hotels:        []models.Hotel{{CheckIn: "2024-12-15", CheckOut: "2024-12-18", Nights: 2}, {CheckIn: "2024-12-18", CheckOut: "2024-12-22", Nights: 4}},
+			wantNights:    []int{3, 4},
+			wantConflicts: []string{"hotel_nights"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := ruleRequest("2024-12-15", "2024-12-22", tt.days...)
+			request.Trip.Duration = tt.duration
+			request.Hotels = tt.hotels
+
+			derived, conflicts := deriveFields(request)
+
+			if tt.wantDuration != "" && derived.Trip.Duration != tt.wantDuration {
+				t.Errorf("duration = %q, want %q", derived.Trip.Duration, tt.wantDuration)
+			}
+			for i, want := range tt.wantDates {
+				if got := derived.Itinerary.Days[i].Date; got != want {
+					t.Errorf("days[%d].date = %q, want %q", i, got, want)
+				}
+			}
+			for i, want := range tt.wantNights {
+				if got := derived.Hotels[i].Nights; got != want {
+					t.Errorf("hotels[%d].nights = %d, want %d", i, got, want)
+				}
+			}
+
+			var rules []string
+			for _, conflict := range conflicts {
+				rules = append(rules, conflict.rule)
+			}
+			if !equalStrings(rules, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", rules, tt.wantConflicts)
+			}
+
+			if request.Trip.Duration != tt.duration || len(request.Itinerary.Days) > 0 && request.Itinerary.Days[0].Date != tt.days[0].Date {
+				t.Error("deriveFields() modified the request")
+			}
+		})
+	}
+}
+
+func TestSameDuration(t *testing.T) {
+	tests := []struct {
+		provided string
+		want     bool
+	}{
+		{"8 Days 7 Nights", true},
+		{"8 days / 7 nights", true},
+		{"8 Days", true},
+		{"8 Days 6 Nights", false},
+		{"7 Days 6 Nights", false},
+		{"one week", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameDuration(tt.provided, "8 Days 7 Nights"); got != tt.want {
+			t.Errorf("sameDuration(%q) = %v, want %v", tt.provided, got, tt.want)
+		}
+	}
+}
+
+func TestSamplesHaveNoDurationConflict(t *testing.T) {
+	for _, name := range []string{"europe_honeymoon.json"} {
+		t.Run(name, func(t *testing.T) {
+			_, conflicts := deriveFields(loadSample(t, name))
+			for _, conflict := range conflicts {
+				if conflict.rule == "trip_duration" {
+					t.Errorf("sample duration conflicts with its dates: %s", conflict.violation.message)
+				}
+			}
+		})
+	}
+}
@@ -1,59 +1,555 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
 	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrPDFNotFound = errors.New("pdf not found")
+
+const (
+	StorageModeVersion   = "version"
+	StorageModeOverwrite = "overwrite"
+)
+
+// saveMu serialises saves so two renders of the same trip cannot pick the
+// same version number or overwrite target. It only covers this process;
+// replicas sharing a bucket may still race on version numbers.
+var saveMu sync.Mutex
+
 type FileService struct {
-	storagePath string
+	storage       Storage
+	storageMode   string
+	presignExpiry time.Duration
+
+	// indexed is set once name indexes are known to exist; guarded by saveMu.
+	indexed bool
 }
 
-func NewFileService() *FileService {
-	return &FileService{
-		storagePath: config.AppConfig.PDF.StoragePath,
+func NewFileService() (*FileService, error) {
+	storage, err := NewStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise PDF storage: %w", err)
+	}
+
+	logrus.WithField("backend", config.AppConfig.PDF.StorageBackend).Info("PDF storage initialised")
+
+	s := &FileService{
+		storage:       storage,
+		storageMode:   config.AppConfig.PDF.StorageMode,
+		presignExpiry: config.AppConfig.PDF.S3.PresignExpiry,
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	if err := s.ensureIndexed(context.Background()); err != nil {
+		// SavePDF tries again before it needs the indexes.
+		logrus.WithError(err).Warn("Failed to index stored PDFs")
 	}
+
+	return s, nil
 }
 
-func (s *FileService) SavePDF(pdfData []byte, filename string) (string, error) {
-	err := utils.EnsureDirectory(s.storagePath)
+// SavePDF stores the PDF under a unique ID with a metadata object next to
+// it and returns its storage key. filename is the readable name offered on
+// download. In version mode earlier documents with the same name are kept
+// and the new one gets the next version; in overwrite mode the latest one is
+// replaced in place. An empty mode uses pdf.storage_mode. info is completed
+// with the ID, content hash, version, size and timestamps.
+func (s *FileService) SavePDF(ctx context.Context, pdfData []byte, filename, mode string, info *models.FileInfoResponse) (string, error) {
+	if mode == "" {
+		mode = s.storageMode
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	if err := s.ensureIndexed(ctx); err != nil {
+		return "", err
+	}
+	index, err := s.readNameIndex(ctx, filename)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create storage directory")
-		return "", fmt.Errorf("failed to create storage directory: %w", err)
+		return "", err
+	}
+
+	info.ID = uuid.NewString()
+	info.Version = 1
+	if latest, ok := index.latest(); ok {
+		if mode == StorageModeOverwrite {
+			info.Version = latest.Version
+			if isLegacyFileID(latest.ID) {
+				// Legacy files are keyed by name, so replace them with a new ID.
+				s.removeLegacy(ctx, latest)
+				index.remove(latest.ID)
+			} else {
+				info.ID = latest.ID
+				// Images and the source of the replaced document are stale.
+				s.deleteArtifacts(ctx, info.ID)
+			}
+		} else {
+			info.Version = latest.Version + 1
+		}
 	}
-	
-	filePath := filepath.Join(s.storagePath, filename)
-	
-	err = os.WriteFile(filePath, pdfData, 0644)
+
+	key := s.storedName(info.ID)
+
+	err = s.storage.Put(ctx, key, pdfData, "application/pdf")
 	if err != nil {
-		logrus.WithError(err).WithField("filePath", filePath).Error("Failed to write PDF file")
+		logrus.WithError(err).WithField("key", key).Error("Failed to write PDF file")
 		return "", fmt.Errorf("failed to write PDF file: %w", err)
 	}
-	
+
+	info.BaseName = filename
+	info.FileName = utils.VersionedFilename(filename, info.Version)
+	info.ContentHash = utils.ContentHash(pdfData)
+	info.FileSize = int64(len(pdfData))
+	info.ModTime = time.Now()
+	info.ContentType = "application/pdf"
+	info.Extension = path.Ext(filename)
+
+	if err := s.writeMetadata(ctx, info); err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Failed to write PDF metadata")
+	}
+	index.put(indexedDocument{ID: info.ID, FileName: info.FileName, Version: info.Version})
+	if err := s.writeNameIndex(ctx, index); err != nil {
+		// The PDF is stored; only the next version number may repeat.
+		logrus.WithError(err).WithField("key", key).Warn("Failed to update name index")
+	}
+
 	logrus.WithFields(logrus.Fields{
-		"filePath": filePath,
-		"fileSize": len(pdfData),
+		"id":          info.ID,
+		"key":         key,
+		"fileName":    info.FileName,
+		"version":     info.Version,
+		"storageMode": mode,
+		"fileSize":    len(pdfData),
 	}).Info("PDF file saved successfully")
-	
-	return filePath, nil
+
+	return key, nil
+}
+
+func (s *FileService) removeLegacy(ctx context.Context, info indexedDocument) {
+	if err := s.storage.Delete(ctx, info.FileName); err != nil {
+		logrus.WithError(err).WithField("fileName", info.FileName).Warn("Failed to remove overwritten PDF")
+	}
+	s.storage.Delete(ctx, s.metadataKey(info.ID))
 }
 
-func (s *FileService) ReadPDF(filePath string) ([]byte, error) {
-	pdfData, err := os.ReadFile(filePath)
+// storedName is the storage key of a document written with a unique ID.
+func (s *FileService) storedName(id string) string {
+	return id + ".pdf"
+}
+
+// isLegacyFileID reports whether id belongs to a file stored under its
+// readable name, before documents were given unique IDs.
+func isLegacyFileID(id string) bool {
+	_, err := uuid.Parse(id)
+	return len(id) != 36 || err != nil
+}
+
+func (s *FileService) keyFor(info *models.FileInfoResponse) string {
+	if isLegacyFileID(info.ID) {
+		return info.FileName
+	}
+	return s.storedName(info.ID)
+}
+
+func (s *FileService) ReadPDF(ctx context.Context, key string) ([]byte, error) {
+	pdfData, err := readObject(ctx, s.storage, key)
 	if err != nil {
-		logrus.WithError(err).WithField("filePath", filePath).Error("Failed to read PDF file")
+		logrus.WithError(err).WithField("key", key).Error("Failed to read PDF file")
 		return nil, fmt.Errorf("failed to read PDF file: %w", err)
 	}
-	
+
 	logrus.WithFields(logrus.Fields{
-		"filePath": filePath,
+		"key":      key,
 		"fileSize": len(pdfData),
 	}).Info("PDF file read successfully")
-	
+
 	return pdfData, nil
 }
+
+// OpenPDF streams a stored PDF. Callers must close the reader.
+func (s *FileService) OpenPDF(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	reader, object, err := s.storage.Get(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, 0, ErrPDFNotFound
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("key", key).Error("Failed to open PDF file")
+		return nil, 0, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	return reader, object.Size, nil
+}
+
+// PresignURL returns a direct backend download URL for a stored PDF, or
+// ErrPresignNotSupported when the backend cannot serve files itself.
+func (s *FileService) PresignURL(ctx context.Context, key string) (string, error) {
+	return s.storage.PresignURL(ctx, key, s.presignExpiry)
+}
+
+// GetPDF resolves a file ID to its metadata and storage key.
+func (s *FileService) GetPDF(ctx context.Context, id string) (*models.FileInfoResponse, string, error) {
+	if !utils.IsValidFileID(id) {
+		return nil, "", ErrPDFNotFound
+	}
+
+	info, err := s.readMetadata(ctx, id)
+	if err == nil {
+		key := s.keyFor(info)
+		if _, err := s.storage.Stat(ctx, key); err == nil {
+			return info, key, nil
+		}
+		return nil, "", ErrPDFNotFound
+	}
+
+	// The metadata write may have failed after the PDF itself was saved.
+	// Legacy files without metadata got it when storage was indexed.
+	if isLegacyFileID(id) {
+		return nil, "", ErrPDFNotFound
+	}
+	key := s.storedName(id)
+	object, err := s.storage.Stat(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, "", ErrPDFNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to look up PDF: %w", err)
+	}
+	return &models.FileInfoResponse{
+		ID:          id,
+		FileName:    key,
+		BaseName:    key,
+		Version:     1,
+		FileSize:    object.Size,
+		ModTime:     object.ModTime,
+		ContentType: "application/pdf",
+		Extension:   path.Ext(key),
+	}, key, nil
+}
+
+// ListPDFs returns stored PDFs, newest first, filtered and paginated.
+func (s *FileService) ListPDFs(ctx context.Context, filter models.PDFListFilter) (*models.PDFListResponse, error) {
+	infos, err := s.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := strings.ToLower(filter.Customer)
+	destination := strings.ToLower(filter.Destination)
+
+	matched := []models.FileInfoResponse{}
+	for _, info := range infos {
+		if customer != "" &&
+			!strings.Contains(strings.ToLower(info.CustomerName), customer) &&
+			!strings.Contains(strings.ToLower(info.CustomerEmail), customer) {
+			continue
+		}
+		if destination != "" && !strings.Contains(strings.ToLower(info.Destination), destination) {
+			continue
+		}
+		if !filter.From.IsZero() && info.ModTime.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !info.ModTime.Before(filter.To) {
+			continue
+		}
+		matched = append(matched, info)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ModTime.After(matched[j].ModTime)
+	})
+
+	start, end := pageBounds(filter.Page, filter.PageSize, len(matched))
+
+	return &models.PDFListResponse{
+		Items:    matched[start:end],
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    len(matched),
+	}, nil
+}
+
+// pageBounds returns the slice bounds of a 1-based page within total items.
+// Pages past the end are empty; the bounds are computed by division so a
+// huge page number cannot overflow.
+func pageBounds(page, pageSize, total int) (int, int) {
+	if page < 1 || pageSize < 1 || page-1 > total/pageSize {
+		return total, total
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	if pageSize > total-start {
+		return start, total
+	}
+	return start, start + pageSize
+}
+
+// DeletePDF removes a PDF and its metadata.
+func (s *FileService) DeletePDF(ctx context.Context, id string) error {
+	info, key, err := s.GetPDF(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Delete(ctx, key); err != nil {
+		logrus.WithError(err).WithField("key", key).Error("Failed to delete PDF file")
+		return fmt.Errorf("failed to delete PDF file: %w", err)
+	}
+	if err := s.storage.Delete(ctx, s.metadataKey(id)); err != nil {
+		logrus.WithError(err).WithField("id", id).Warn("Failed to delete PDF metadata")
+	}
+	s.deleteArtifacts(ctx, id)
+	s.unindex(ctx, info)
+
+	logrus.WithFields(logrus.Fields{
+		"id":       id,
+		"fileName": info.FileName,
+	}).Info("PDF file deleted")
+
+	return nil
+}
+
+// scan lists every PDF in storage, merging in stored metadata when present.
+func (s *FileService) scan(ctx context.Context) ([]models.FileInfoResponse, error) {
+	objects, err := s.storage.List(ctx, "")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list PDF storage")
+		return nil, fmt.Errorf("failed to list PDF storage: %w", err)
+	}
+
+	var infos []models.FileInfoResponse
+	for _, object := range objects {
+		if strings.Contains(object.Key, "/") || !strings.EqualFold(path.Ext(object.Key), ".pdf") {
+			continue
+		}
+
+		id := utils.FileID(object.Key)
+		info, err := s.readMetadata(ctx, id)
+		if err != nil {
+			info = &models.FileInfoResponse{
+				ID:          id,
+				FileName:    object.Key,
+				ContentType: "application/pdf",
+				Extension:   path.Ext(object.Key),
+			}
+		}
+		if info.BaseName == "" {
+			// Files stored under their readable name count as the first version.
+			info.BaseName = info.FileName
+			info.Version = 1
+		}
+		info.FileSize = object.Size
+		info.ModTime = object.ModTime
+		infos = append(infos, *info)
+	}
+
+	return infos, nil
+}
+
+func (s *FileService) metadataKey(id string) string {
+	return id + ".json"
+}
+
+func (s *FileService) writeMetadata(ctx context.Context, info *models.FileInfoResponse) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, s.metadataKey(info.ID), data, "application/json")
+}
+
+func (s *FileService) readMetadata(ctx context.Context, id string) (*models.FileInfoResponse, error) {
+	data, err := readObject(ctx, s.storage, s.metadataKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var info models.FileInfoResponse
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Artifacts derived from a PDF, such as its source document and page images,
+// are stored under "<id>/" so scan never mistakes them for documents.
+
+var pageImageNamePattern = regexp.MustCompile(`^(cover|page-([0-9]+)-([0-9]+)dpi)\.(png|jpg)$`)
+
+func (s *FileService) artifactKey(id, name string) string {
+	return id + "/" + name
+}
+
+func (s *FileService) saveSource(ctx context.Context, id string, doc *renderDocument) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, s.artifactKey(id, "source.json"), data, "application/json")
+}
+
+func (s *FileService) readSource(ctx context.Context, id string) (*renderDocument, error) {
+	data, err := readObject(ctx, s.storage, s.artifactKey(id, "source.json"))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, ErrNoDocumentSource
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF source: %w", err)
+	}
+
+	var doc renderDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode PDF source: %w", err)
+	}
+	return &doc, nil
+}
+
+func pageImageName(capture *pageCapture, page int) string {
+	if capture.cover {
+		return "cover." + capture.extension()
+	}
+	return fmt.Sprintf("page-%d-%ddpi.%s", page, capture.dpi, capture.extension())
+}
+
+func pageImage(id, name string, page int, capture *pageCapture, size int64) models.PageImage {
+	return models.PageImage{
+		Page:   page,
+		Name:   name,
+		URL:    fmt.Sprintf("/api/v1/pdfs/%s/images/%s", id, name),
+		Format: capture.format,
+		DPI:    capture.dpi,
+		Size:   size,
+	}
+}
+
+func (s *FileService) savePageImages(ctx context.Context, id string, capture *pageCapture) ([]models.PageImage, error) {
+	contentType := "image/" + capture.format
+
+	images := make([]models.PageImage, 0, len(capture.images))
+	for i, data := range capture.images {
+		name := pageImageName(capture, i+1)
+		if err := s.storage.Put(ctx, s.artifactKey(id, name), data, contentType); err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", name, err)
+		}
+		images = append(images, pageImage(id, name, i+1, capture, int64(len(data))))
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no page images were captured")
+	}
+	return images, nil
+}
+
+// findPageImages returns stored page images matching a capture's format and
+// DPI, in page order.
+func (s *FileService) findPageImages(ctx context.Context, id string, capture *pageCapture) ([]models.PageImage, error) {
+	objects, err := s.storage.List(ctx, s.artifactKey(id, "page-"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list page images: %w", err)
+	}
+
+	var images []models.PageImage
+	for _, object := range objects {
+		name := path.Base(object.Key)
+		match := pageImageNamePattern.FindStringSubmatch(name)
+		if match == nil || match[3] != strconv.Itoa(capture.dpi) || match[4] != capture.extension() {
+			continue
+		}
+		page, _ := strconv.Atoi(match[2])
+		images = append(images, pageImage(id, name, page, capture, object.Size))
+	}
+
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Page < images[j].Page
+	})
+	return images, nil
+}
+
+// OpenImage streams a stored page image. Callers must close the reader.
+func (s *FileService) OpenImage(ctx context.Context, id, name string) (io.ReadCloser, ObjectInfo, error) {
+	if !utils.IsValidFileID(id) || !pageImageNamePattern.MatchString(name) {
+		return nil, ObjectInfo{}, ErrPDFNotFound
+	}
+
+	reader, object, err := s.storage.Get(ctx, s.artifactKey(id, name))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, ObjectInfo{}, ErrPDFNotFound
+	}
+	if err != nil {
+		return nil, ObjectInfo{}, fmt.Errorf("failed to open page image: %w", err)
+	}
+
+	object.ContentType = "image/png"
+	if path.Ext(name) == ".jpg" {
+		object.ContentType = "image/jpeg"
+	}
+	return reader, *object, nil
+}
+
+// artifactUsage returns the bytes stored for each document besides its PDF,
+// its metadata and everything under "<id>/", keyed by document ID. IDs whose
+// PDF is gone are included so cleanup can find them.
+func (s *FileService) artifactUsage(ctx context.Context) (map[string]int64, error) {
+	objects, err := s.storage.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PDF storage: %w", err)
+	}
+
+	usage := make(map[string]int64)
+	for _, object := range objects {
+		id, _, nested := strings.Cut(object.Key, "/")
+		if !nested {
+			if !strings.EqualFold(path.Ext(object.Key), ".json") {
+				continue
+			}
+			id = strings.TrimSuffix(object.Key, path.Ext(object.Key))
+		}
+		if !utils.IsValidFileID(id) {
+			continue
+		}
+		usage[id] += object.Size
+	}
+	return usage, nil
+}
+
+// deleteOrphan removes the metadata and artifacts of a document whose PDF no
+// longer exists.
+func (s *FileService) deleteOrphan(ctx context.Context, id string) error {
+	if err := s.storage.Delete(ctx, s.metadataKey(id)); err != nil {
+		return fmt.Errorf("failed to delete PDF metadata: %w", err)
+	}
+	s.deleteArtifacts(ctx, id)
+	return nil
+}
+
+func (s *FileService) deleteArtifacts(ctx context.Context, id string) {
+	objects, err := s.storage.List(ctx, id+"/")
+	if err != nil {
+		logrus.WithError(err).WithField("id", id).Warn("Failed to list PDF artifacts")
+		return
+	}
+	for _, object := range objects {
+		if err := s.storage.Delete(ctx, object.Key); err != nil {
+			logrus.WithError(err).WithField("key", object.Key).Warn("Failed to delete PDF artifact")
+		}
+	}
+}
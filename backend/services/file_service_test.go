@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/google/uuid"
+)
+
+// listCountingStorage records the prefixes storage is listed with.
+type listCountingStorage struct {
+	Storage
+
+	mu    sync.Mutex
+	lists []string
+}
+
+func (s *listCountingStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.Lock()
+	s.lists = append(s.lists, prefix)
+	s.mu.Unlock()
+	return s.Storage.List(ctx, prefix)
+}
+
+func (s *listCountingStorage) fullScans() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, prefix := range s.lists {
+		if prefix == "" {
+			n++
+		}
+	}
+	return n
+}
+
+func newCountingFileService(t *testing.T, storage Storage) (*FileService, *listCountingStorage) {
+	t.Helper()
+
+	counting := &listCountingStorage{Storage: storage}
+	s := &FileService{storage: counting, storageMode: StorageModeVersion}
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	if err := s.ensureIndexed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s, counting
+}
+
+func TestNewFileServiceRejectsUnknownBackend(t *testing.T) {
+	saved := config.AppConfig.PDF.StorageBackend
+	config.AppConfig.PDF.StorageBackend = "floppy"
+	t.Cleanup(func() { config.AppConfig.PDF.StorageBackend = saved })
+
+	if _, err := NewFileService(); err == nil || !strings.Contains(err.Error(), `unknown storage backend "floppy"`) {
+		t.Fatalf("NewFileService() error = %v, want an unknown backend error", err)
+	}
+}
+
+func TestSavePDFVersions(t *testing.T) {
+	tests := []struct {
+		name         string
+		saves        []string // "<file name>:<mode>"
+		wantVersions []int
+		wantSameID   []bool // whether each save reused the previous save's ID
+	}{
+		{"new names", []string{"a.pdf:version", "b.pdf:version"}, []int{1, 1}, []bool{false, false}},
+		{"versions", []string{"a.pdf:version", "a.pdf:version", "a.pdf:"}, []int{1, 2, 3}, []bool{false, false, false}},
+		{"overwrite", []string{"a.pdf:version", "a.pdf:version", "a.pdf:overwrite"}, []int{1, 2, 2}, []bool{false, false, true}},
+		{"overwrite first", []string{"a.pdf:overwrite", "a.pdf:overwrite"}, []int{1, 1}, []bool{false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, counting := newCountingFileService(t, NewMemoryStorage())
+			scans := counting.fullScans()
+
+			var previousID string
+			for i, save := range tt.saves {
+				name, mode, _ := strings.Cut(save, ":")
+				info := &models.FileInfoResponse{}
+				if _, err := s.SavePDF(context.Background(), []byte("%PDF "+save), name, mode, info); err != nil {
+					t.Fatalf("SavePDF(%s) error = %v", save, err)
+				}
+				if info.Version != tt.wantVersions[i] {
+					t.Errorf("save %d version = %d, want %d", i, info.Version, tt.wantVersions[i])
+				}
+				if sameID := info.ID == previousID; sameID != tt.wantSameID[i] {
+					t.Errorf("save %d reused ID = %v, want %v", i, sameID, tt.wantSameID[i])
+				}
+				if want := utils.VersionedFilename(name, info.Version); info.FileName != want {
+					t.Errorf("save %d FileName = %q, want %q", i, info.FileName, want)
+				}
+				previousID = info.ID
+			}
+
+			if got := counting.fullScans() - scans; got != 0 {
+				t.Errorf("saves listed the whole store %d times", got)
+			}
+		})
+	}
+}
+
+func TestGetPDF(t *testing.T) {
+	s, counting := newCountingFileService(t, NewMemoryStorage())
+	ctx := context.Background()
+
+	saved := &models.FileInfoResponse{CustomerName: "Saved"}
+	if _, err := s.SavePDF(ctx, []byte("%PDF saved"), "saved.pdf", "", saved); err != nil {
+		t.Fatal(err)
+	}
+	// A PDF whose metadata write failed is still found by its ID.
+	bare := uuid.NewString()
+	s.storage.Put(ctx, s.storedName(bare), []byte("%PDF bare"), "application/pdf")
+
+	scans := counting.fullScans()
+	tests := []struct {
+		name     string
+		id       string
+		wantErr  error
+		wantName string
+	}{
+		{"saved", saved.ID, nil, "saved.pdf"},
+		{"without metadata", bare, nil, bare + ".pdf"},
+		{"unknown", uuid.NewString(), ErrPDFNotFound, ""},
+		{"unknown legacy", "0123456789abcdef0123456789abcdef", ErrPDFNotFound, ""},
+		{"invalid", "../etc/passwd", ErrPDFNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, _, err := s.GetPDF(ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPDF(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && info.FileName != tt.wantName {
+				t.Errorf("FileName = %q, want %q", info.FileName, tt.wantName)
+			}
+		})
+	}
+
+	if got := counting.fullScans() - scans; got != 0 {
+		t.Errorf("lookups listed the whole store %d times", got)
+	}
+}
+
+func TestFileServiceIndexesLegacyFiles(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+
+	// Stored before IDs, metadata and indexes existed.
+	legacyName := "Bali_2024-01-01_to_2024-01-05_2pax_Jane.pdf"
+	storage.Put(ctx, legacyName, []byte("%PDF legacy"), "application/pdf")
+
+	s, counting := newCountingFileService(t, storage)
+	if got := counting.fullScans(); got != 1 {
+		t.Fatalf("indexing listed the whole store %d times, want 1", got)
+	}
+
+	info, key, err := s.GetPDF(ctx, utils.FileID(legacyName))
+	if err != nil {
+		t.Fatalf("GetPDF(legacy) error = %v", err)
+	}
+	if key != legacyName || info.Version != 1 {
+		t.Errorf("GetPDF(legacy) = %q version %d, want %q version 1", key, info.Version, legacyName)
+	}
+
+	next := &models.FileInfoResponse{}
+	if _, err := s.SavePDF(ctx, []byte("%PDF next"), legacyName, StorageModeVersion, next); err != nil {
+		t.Fatal(err)
+	}
+	if next.Version != 2 {
+		t.Errorf("version after legacy file = %d, want 2", next.Version)
+	}
+
+	// A second service on the same store does not index it again.
+	_, again := newCountingFileService(t, storage)
+	if got := again.fullScans(); got != 0 {
+		t.Errorf("reindexing listed the whole store %d times, want 0", got)
+	}
+}
+
+func TestDeletePDFUpdatesIndex(t *testing.T) {
+	s, _ := newCountingFileService(t, NewMemoryStorage())
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		info := &models.FileInfoResponse{}
+		if _, err := s.SavePDF(ctx, []byte("%PDF"), "trip.pdf", StorageModeVersion, info); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, info.ID)
+	}
+
+	for _, id := range ids {
+		if err := s.DeletePDF(ctx, id); err != nil {
+			t.Fatalf("DeletePDF(%s) error = %v", id, err)
+		}
+	}
+	if _, err := s.storage.Stat(ctx, s.nameIndexKey("trip.pdf")); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("empty name index left behind, Stat error = %v", err)
+	}
+
+	info := &models.FileInfoResponse{}
+	if _, err := s.SavePDF(ctx, []byte("%PDF"), "trip.pdf", StorageModeVersion, info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != 1 {
+		t.Errorf("version after deleting every document = %d, want 1", info.Version)
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name               string
+		page, pageSize     int
+		total              int
+		wantStart, wantEnd int
+	}{
+		{"first page", 1, 20, 45, 0, 20},
+		{"middle page", 2, 20, 45, 20, 40},
+		{"partial last page", 3, 20, 45, 40, 45},
+		{"exact last page", 2, 20, 40, 20, 40},
+		{"past the end", 4, 20, 45, 45, 45},
+		{"no items", 1, 20, 0, 0, 0},
+		{"huge page", math.MaxInt, 100, 45, 45, 45},
+		{"huge page size", 1, math.MaxInt, 45, 0, 45},
+		{"huge page and page size", math.MaxInt, math.MaxInt, 45, 45, 45},
+		{"page zero", 0, 20, 45, 45, 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := pageBounds(tt.page, tt.pageSize, tt.total)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("pageBounds(%d, %d, %d) = [%d:%d], want [%d:%d]",
+					tt.page, tt.pageSize, tt.total, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
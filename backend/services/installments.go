@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// PaymentScheduler generates installment plans from percentage, fixed and
+// remainder rules, with due dates counted back from departure onto business
+// days.
+type PaymentScheduler struct {
+	rules    []models.InstallmentRule
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+func NewPaymentScheduler() *PaymentScheduler {
+	cfg := config.AppConfig.Payments
+
+	scheduler := &PaymentScheduler{
+		weekend:  make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+	}
+
+	for _, rule := range cfg.Schedule {
+		installment := models.InstallmentRule{
+			Name:       rule.Name,
+			Percent:    rule.Percent,
+			DaysBefore: rule.DaysBefore,
+		}
+		if rule.Amount != "" {
+			amount, err := models.ParseMoney(rule.Amount)
+			if err != nil {
+				logrus.WithError(err).WithField("installment", rule.Name).Warn("Invalid installment amount, dropping the default schedule")
+				scheduler.rules = nil
+				break
+			}
+			installment.Amount = amount
+		}
+		scheduler.rules = append(scheduler.rules, installment)
+	}
+
+	days := map[string]time.Weekday{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		days[strings.ToLower(d.String())] = d
+	}
+	for _, name := range cfg.Weekend {
+		day, ok := days[strings.ToLower(name)]
+		if !ok {
+			logrus.WithField("day", name).Warn("Unknown weekend day, ignoring it")
+			continue
+		}
+		scheduler.weekend[day] = true
+	}
+	if len(scheduler.weekend) == 7 {
+		logrus.Warn("Every day is a weekend day, due dates will not be moved")
+		scheduler.weekend = map[time.Weekday]bool{}
+	}
+
+	for _, holiday := range cfg.Holidays {
+		date, err := utils.ParseDate(holiday)
+		if err != nil {
+			logrus.WithField("holiday", holiday).Warn("Invalid holiday date, ignoring it")
+			continue
+		}
+		scheduler.holidays[formatRuleDate(date)] = true
+	}
+
+	return scheduler
+}
+
+// Apply fills in the installments of a request that has none, from its own
+// payment.schedule or the configured one. It needs the total and the trip
+// start date; when either is missing the installments are left empty for
+// validation to report. The request should be a copy from deriveFields.
+func (p *PaymentScheduler) Apply(request *models.ItineraryRequest) error {
+	payment := &request.Payment
+	if len(payment.Schedule) > 0 && len(payment.Installments) > 0 {
+		return &fieldError{"payment.schedule", errors.New("give either installments or a schedule, not both")}
+	}
+	if len(payment.Installments) > 0 {
+		return nil
+	}
+
+	rules := payment.Schedule
+	if len(rules) == 0 {
+		rules = p.rules
+	}
+	if len(rules) == 0 || !payment.TotalAmount.IsSet() || !payment.TotalAmount.Valid() {
+		return nil
+	}
+
+	start, err := utils.ParseDate(request.Trip.StartDate)
+	if err != nil {
+		return nil
+	}
+
+	booking := time.Now()
+	if payment.BookingDate != "" {
+		booking, err = utils.ParseDate(payment.BookingDate)
+		if err != nil {
+			return &fieldError{"payment.bookingDate", fmt.Errorf("invalid booking date %q", payment.BookingDate)}
+		}
+	}
+	booking = time.Date(booking.Year(), booking.Month(), booking.Day(), 0, 0, 0, 0, time.UTC)
+
+	installments, err := p.generate(rules, requestCurrency(request), payment.TotalAmount, booking, start)
+	if err != nil {
+		return err
+	}
+	payment.Installments = installments
+	return nil
+}
+
+func (p *PaymentScheduler) generate(rules []models.InstallmentRule, currency *utils.Currency, total models.Money, booking, start time.Time) ([]models.Installment, error) {
+	total, err := total.Rescale(currency.Decimals)
+	if err != nil {
+		return nil, &fieldError{"payment.totalAmount", fmt.Errorf("%s allows at most %d decimal places", currency.Code, currency.Decimals)}
+	}
+	total = total.WithCurrency(currency.Code)
+
+	installments := make([]models.Installment, len(rules))
+	remainder := -1
+	allPercent := true
+	percentSum := 0.0
+	sum, _ := models.NewMoney(0, currency.Code)
+
+	for i, rule := range rules {
+		field := fmt.Sprintf("payment.schedule[%d]", i)
+		installments[i].InstallmentName = rule.Name
+		installments[i].DueDate = formatRuleDate(p.dueDate(rule, booking, start))
+
+		switch {
+		case rule.Percent > 0 && rule.Amount.IsSet():
+			return nil, &fieldError{field, errors.New("give either percent or amount, not both")}
+		case rule.Percent > 0:
+			installments[i].Amount = total.Percent(rule.Percent)
+			percentSum += rule.Percent
+		case rule.Amount.IsSet():
+			amount, err := rule.Amount.Rescale(currency.Decimals)
+			if err != nil {
+				return nil, &fieldError{field + ".amount", fmt.Errorf("%s allows at most %d decimal places", currency.Code, currency.Decimals)}
+			}
+			installments[i].Amount = amount.WithCurrency(currency.Code)
+			allPercent = false
+		default:
+			if remainder >= 0 {
+				return nil, &fieldError{field, fmt.Errorf("only one installment can take the remainder; %q already does", rules[remainder].Name)}
+			}
+			remainder = i
+			continue
+		}
+		sum = sum.Add(installments[i].Amount)
+	}
+
+	rest := total.Sub(sum)
+	switch {
+	case rest.IsNegative():
+		return nil, &fieldError{"payment.schedule", fmt.Errorf("installments add up to %s, more than the total of %s",
+			currency.FormatMoney(sum), currency.FormatMoney(total))}
+	case remainder >= 0:
+		installments[remainder].Amount = rest
+	case allPercent && math.Round(percentSum*100) == 10000:
+		// Percentages that add up to 100 cover the total exactly; the last
+		// installment absorbs the rounding.
+		last := &installments[len(installments)-1]
+		last.Amount = last.Amount.Add(rest)
+	}
+
+	return installments, nil
+}
+
+// dueDate counts back from departure and moves the date to the business day
+// before it, but never before booking.
+func (p *PaymentScheduler) dueDate(rule models.InstallmentRule, booking, start time.Time) time.Time {
+	if rule.DaysBefore == nil {
+		return booking
+	}
+
+	due := start.AddDate(0, 0, -*rule.DaysBefore)
+	for !p.isBusinessDay(due) && due.After(booking) {
+		due = due.AddDate(0, 0, -1)
+	}
+	if due.Before(booking) {
+		return booking
+	}
+	return due
+}
+
+func (p *PaymentScheduler) isBusinessDay(day time.Time) bool {
+	return !p.weekend[day.Weekday()] && !p.holidays[formatRuleDate(day)]
+}
+
+// trackPayment sets the status of every installment as of today: paid when
+// marked paid or given a paidOn date, overdue once its due date has passed,
+// and pending otherwise. The payment's status, advance and outstanding
+// balance follow from them.
+func trackPayment(payment models.Payment, today time.Time) models.Payment {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	tracked := payment
+	tracked.Installments = make([]models.Installment, len(payment.Installments))
+
+	var balance models.Money
+	paid, overdue := 0, 0
+	for i, installment := range payment.Installments {
+		switch {
+		case installment.Status == models.PaymentPaid || installment.PaidOn != "":
+			installment.Status = models.PaymentPaid
+			paid++
+		default:
+			installment.Status = models.PaymentPending
+			if due, err := utils.ParseDate(installment.DueDate); err == nil && due.Before(today) {
+				installment.Status = models.PaymentOverdue
+				overdue++
+			}
+			balance = balance.Add(installment.Amount)
+		}
+		tracked.Installments[i] = installment
+	}
+
+	switch {
+	case len(payment.Installments) > 0 && paid == len(payment.Installments):
+		tracked.Status = models.PaymentPaid
+	case overdue > 0:
+		tracked.Status = models.PaymentOverdue
+	case paid > 0:
+		tracked.Status = models.PaymentPartiallyPaid
+	default:
+		tracked.Status = models.PaymentPending
+	}
+
+	if len(payment.Installments) > 0 {
+		tracked.AdvanceAmount = payment.Installments[0].Amount
+	}
+	tracked.BalanceAmount = balance
+	return tracked
+}
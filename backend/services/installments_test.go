@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+func newTestPaymentScheduler(holidays ...string) *PaymentScheduler {
+	scheduler := &PaymentScheduler{
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: make(map[string]bool),
+	}
+	for _, holiday := range holidays {
+		scheduler.holidays[holiday] = true
+	}
+	return scheduler
+}
+
+func daysBefore(n int) *int { return &n }
+
+func TestPaymentSchedulerGenerate(t *testing.T) {
+	inr := utils.CurrencyOrDefault("INR", "")
+	jpy := utils.CurrencyOrDefault("JPY", "")
+	// Departure is Monday 2025-03-10; 45 days before is a Friday and 15
+	// days before is a Sunday.
+	booking := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		rules       []models.InstallmentRule
+		currency    *utils.Currency
+		total       string
+		wantAmounts []string
+		wantDue     []string
+		wantField   string
+	}{
+		{
+			name: "percent with a remainder",
+			rules: []models.InstallmentRule{
+				{Name: "Advance", Percent: 30},
+				{Name: "Second", Percent: 50, DaysBefore: daysBefore(45)},
+				{Name: "Balance", DaysBefore: daysBefore(15)},
+			},
+			currency:    inr,
+			total:       "100000",
+			wantAmounts: []string{"30000.00", "50000.00", "20000.00"},
+			wantDue:     []string{"2025-01-01", "2025-01-24", "2025-02-21"},
+		},
+		{
+			name: "percentages adding to 100 absorb rounding",
+			rules: []models.InstallmentRule{
+				{Name: "First", Percent: 33.33},
+				{Name: "Second", Percent: 33.33},
+				{Name: "Third", Percent: 33.34},
+			},
+			currency:    inr,
+			total:       "100.01",
+			wantAmounts: []string{"33.33", "33.33", "33.35"},
+		},
+		{
+			name: "fixed amount with a remainder",
+			rules: []models.InstallmentRule{
+				{Name: "Deposit", Amount: mustParseMoney(t, "5000")},
+				{Name: "Balance", DaysBefore: daysBefore(30)},
+			},
+			currency:    jpy,
+			total:       "120000",
+			wantAmounts: []string{"5000", "115000"},
+		},
+		{
+			name:      "total with more decimals than the currency",
+			rules:     []models.InstallmentRule{{Name: "All"}},
+			currency:  jpy,
+			total:     "1000.50",
+			wantField: "payment.totalAmount",
+		},
+		{
+			name:      "fixed amount with more decimals than the currency",
+			rules:     []models.InstallmentRule{{Name: "Deposit", Amount: mustParseMoney(t, "10.005")}, {Name: "Balance"}},
+			currency:  inr,
+			total:     "1000",
+			wantField: "payment.schedule[0].amount",
+		},
+		{
+			name:      "percent and amount together",
+			rules:     []models.InstallmentRule{{Name: "Deposit", Percent: 10, Amount: mustParseMoney(t, "100")}},
+			currency:  inr,
+			total:     "1000",
+			wantField: "payment.schedule[0]",
+		},
+		{
+			name:      "two remainders",
+			rules:     []models.InstallmentRule{{Name: "A"}, {Name: "B"}},
+			currency:  inr,
+			total:     "1000",
+			wantField: "payment.schedule[1]",
+		},
+		{
+			name:      "installments above the total",
+			rules:     []models.InstallmentRule{{Name: "A", Percent: 80}, {Name: "B", Amount: mustParseMoney(t, "300")}},
+			currency:  inr,
+			total:     "1000",
+			wantField: "payment.schedule",
+		},
+	}
+
+	scheduler := newTestPaymentScheduler()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installments, err := scheduler.generate(tt.rules, tt.currency, mustParseMoney(t, tt.total), booking, start)
+			if tt.wantField != "" {
+				var fe *fieldError
+				if !errors.As(err, &fe) || fe.field != tt.wantField {
+					t.Fatalf("generate() = %v, %v, want a fieldError on %s", installments, err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generate() error = %v", err)
+			}
+
+			var amounts, due []string
+			for _, installment := range installments {
+				amounts = append(amounts, installment.Amount.String())
+				due = append(due, installment.DueDate)
+			}
+			if !equalStrings(amounts, tt.wantAmounts) {
+				t.Errorf("amounts = %v, want %v", amounts, tt.wantAmounts)
+			}
+			if tt.wantDue != nil && !equalStrings(due, tt.wantDue) {
+				t.Errorf("due dates = %v, want %v", due, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestPaymentSchedulerDueDate(t *testing.T) {
+	booking := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		daysBefore *int
+		holidays   []string
+		booking    time.Time
+		want       string
+	}{
+		{"due on booking", nil, nil, booking, "2025-01-01"},
+		{"business day", daysBefore(45), nil, booking, "2025-01-24"},
+		{"weekend moves to Friday", daysBefore(15), nil, booking, "2025-02-21"},
+		{"holiday moves to the day before", daysBefore(45), []string{"2025-01-24"}, booking, "2025-01-23"},
+		{"never before booking", daysBefore(90), nil, booking, "2025-01-01"},
+		{"not moved past booking", daysBefore(15), nil, time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC), "2025-02-22"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := newTestPaymentScheduler(tt.holidays...)
+			rule := models.InstallmentRule{DaysBefore: tt.daysBefore}
+			if got := formatRuleDate(scheduler.dueDate(rule, tt.booking, start)); got != tt.want {
+				t.Errorf("dueDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentSchedulerApply(t *testing.T) {
+	scheduler := newTestPaymentScheduler()
+	scheduler.rules = []models.InstallmentRule{{Name: "Advance", Percent: 40}, {Name: "Balance", DaysBefore: daysBefore(30)}}
+
+	newRequest := func() *models.ItineraryRequest {
+		return &models.ItineraryRequest{
+			Currency: "INR",
+			Trip:     models.Trip{StartDate: "2025-03-10"},
+			Payment:  models.Payment{TotalAmount: mustParseMoney(t, "1000"), BookingDate: "2025-01-01"},
+		}
+	}
+
+	t.Run("configured schedule", func(t *testing.T) {
+		request := newRequest()
+		if err := scheduler.Apply(request); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(request.Payment.Installments); got != 2 {
+			t.Fatalf("installments = %d, want 2", got)
+		}
+		if got := request.Payment.Installments[1].Amount.String(); got != "600.00" {
+			t.Errorf("balance = %s, want 600.00", got)
+		}
+	})
+
+	t.Run("request schedule wins", func(t *testing.T) {
+		request := newRequest()
+		request.Payment.Schedule = []models.InstallmentRule{{Name: "Everything"}}
+		if err := scheduler.Apply(request); err != nil {
+			t.Fatal(err)
+		}
+		if len(request.Payment.Installments) != 1 || request.Payment.Installments[0].InstallmentName != "Everything" {
+			t.Errorf("installments = %+v, want the request's schedule", request.Payment.Installments)
+		}
+	})
+
+	t.Run("schedule and installments together", func(t *testing.T) {
+		request := newRequest()
+		request.Payment.Schedule = []models.InstallmentRule{{Name: "Everything"}}
+		request.Payment.Installments = []models.Installment{{InstallmentName: "Given"}}
+		var fe *fieldError
+		if err := scheduler.Apply(request); !errors.As(err, &fe) || fe.field != "payment.schedule" {
+			t.Errorf("Apply() error = %v, want a fieldError on payment.schedule", err)
+		}
+	})
+
+	t.Run("invalid booking date", func(t *testing.T) {
+		request := newRequest()
+		request.Payment.BookingDate = "yesterday"
+		var fe *fieldError
+		if err := scheduler.Apply(request); !errors.As(err, &fe) || fe.field != "payment.bookingDate" {
+			t.Errorf("Apply() error = %v, want a fieldError on payment.bookingDate", err)
+		}
+	})
+
+	t.Run("missing start date leaves installments empty", func(t *testing.T) {
+		request := newRequest()
+		request.Trip.StartDate = ""
+		if err := scheduler.Apply(request); err != nil || len(request.Payment.Installments) != 0 {
+			t.Errorf("Apply() = %v with %d installments, want none", err, len(request.Payment.Installments))
+		}
+	})
+}
+
+func TestTrackPayment(t *testing.T) {
+	today := time.Date(2025, 2, 1, 15, 0, 0, 0, time.UTC)
+	installment := func(due, status, paidOn string) models.Installment {
+		return models.Installment{Amount: mustParseMoney(t, "100"), DueDate: due, Status: status, PaidOn: paidOn}
+	}
+
+	tests := []struct {
+		name         string
+		installments []models.Installment
+		wantStatus   string
+		wantBalance  string
+	}{
+		{"all paid", []models.Installment{installment("2025-01-01", models.PaymentPaid, ""), installment("2025-03-01", "", "2025-01-20")}, models.PaymentPaid, ""},
+		{"one overdue", []models.Installment{installment("2025-01-01", models.PaymentPaid, ""), installment("2025-01-31", "", "")}, models.PaymentOverdue, "100"},
+		{"due today is not overdue", []models.Installment{installment("2025-02-01", models.PaymentPaid, ""), installment("2025-02-01", "", "")}, models.PaymentPartiallyPaid, "100"},
+		{"nothing paid", []models.Installment{installment("2025-03-01", "", ""), installment("2025-04-01", models.PaymentOverdue, "")}, models.PaymentPending, "200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracked := trackPayment(models.Payment{Installments: tt.installments}, today)
+			if tracked.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", tracked.Status, tt.wantStatus)
+			}
+			if got := tracked.BalanceAmount.String(); got != tt.wantBalance {
+				t.Errorf("balance = %q, want %q", got, tt.wantBalance)
+			}
+			if tracked.Installments[0].Status == "" || &tracked.Installments[0] == &tt.installments[0] {
+				t.Error("trackPayment() did not return its own installments")
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const invoiceKeyPrefix = "invoices/"
+
+// InvoiceService builds proforma invoices and issues their numbers. Numbers
+// run in sequence within each Indian financial year (April to March), such
+// as PI/2026-27/0001, and every issued number is recorded in PDF storage so
+// it is never issued again, even across restarts or by replicas sharing the
+// store.
+type InvoiceService struct {
+	storage      Storage
+	prefix       string
+	digits       int
+	validityDays int
+	sellerGSTIN  string
+	sacCode      string
+	sacCodes     map[string]string
+	gstPercent   float64
+	bank         models.BankDetails
+	terms        []string
+}
+
+// invoiceCounter is the last number issued in a financial year.
+type invoiceCounter struct {
+	FinancialYear string    `json:"financialYear"`
+	Last          int       `json:"last"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// invoiceRecord claims one invoice number.
+type invoiceRecord struct {
+	Number       string    `json:"number"`
+	Sequence     int       `json:"sequence"`
+	IssuedAt     time.Time `json:"issuedAt"`
+	CustomerName string    `json:"customerName"`
+	Destination  string    `json:"destination"`
+}
+
+func NewInvoiceService(fileService *FileService) *InvoiceService {
+	cfg := config.AppConfig.Invoice
+
+	service := &InvoiceService{
+		storage:      fileService.storage,
+		prefix:       cfg.Prefix,
+		digits:       cfg.Digits,
+		validityDays: cfg.ValidityDays,
+		sacCode:      cfg.SACCode,
+		sacCodes:     make(map[string]string),
+		gstPercent:   config.AppConfig.Pricing.GSTPercent,
+		bank: models.BankDetails{
+			AccountName:   cfg.Bank.AccountName,
+			AccountNumber: cfg.Bank.AccountNumber,
+			BankName:      cfg.Bank.BankName,
+			Branch:        cfg.Bank.Branch,
+			IFSC:          cfg.Bank.IFSC,
+			SWIFT:         cfg.Bank.SWIFT,
+			UPI:           cfg.Bank.UPI,
+		},
+		terms: cfg.Terms,
+	}
+
+	if cfg.SellerGSTIN != "" {
+		if gstin := strings.ToUpper(cfg.SellerGSTIN); utils.IsGSTIN(gstin) {
+			service.sellerGSTIN = gstin
+		} else {
+			logrus.WithField("gstin", cfg.SellerGSTIN).Warn("Invalid seller GSTIN, invoices must supply their own")
+		}
+	}
+	if service.digits < 1 {
+		service.digits = 1
+	}
+	if service.validityDays < 1 {
+		service.validityDays = 7
+	}
+	for category, code := range cfg.SACCodes {
+		service.sacCodes[strings.ToLower(category)] = code
+	}
+
+	return service
+}
+
+// Build prepares the invoice for a request priced by the pricing engine, or
+// with the GST inside payment.totalAmount split out when it has no pricing.
+// The request should be a copy from deriveFields with pricing and the
+// payment schedule applied. seller is the company printed on the invoice.
+func (s *InvoiceService) Build(request *models.ItineraryRequest, pricing *models.CostBreakdown, seller models.CompanyInfo, number string, issued time.Time) (*models.Invoice, error) {
+	options := request.Invoice
+	if options == nil {
+		options = &models.InvoiceOptions{}
+	}
+
+	invoice := &models.Invoice{
+		Number:       number,
+		Date:         issued.Format("2006-01-02"),
+		SellerGSTIN:  strings.ToUpper(options.SellerGSTIN),
+		BuyerGSTIN:   strings.ToUpper(options.BuyerGSTIN),
+		BuyerAddress: options.BuyerAddress,
+		Bank:         s.bank,
+		Terms:        s.terms,
+	}
+	if invoice.SellerGSTIN == "" {
+		invoice.SellerGSTIN = s.sellerGSTIN
+	}
+	if invoice.SellerGSTIN == "" {
+		return nil, &fieldError{"invoice.sellerGstin", errors.New("a proforma invoice needs the seller's GSTIN; set invoice.sellerGstin or invoice.seller_gstin in config.yaml")}
+	}
+	if options.Bank != nil {
+		invoice.Bank = *options.Bank
+	}
+	if len(options.Terms) > 0 {
+		invoice.Terms = options.Terms
+	}
+
+	validity := s.validityDays
+	if options.ValidityDays > 0 {
+		validity = options.ValidityDays
+	}
+	invoice.ValidUntil = issued.AddDate(0, 0, validity).Format("2006-01-02")
+
+	sellerState := seller.RegisteredOffice.State
+	switch {
+	case utils.IsGSTIN(invoice.BuyerGSTIN) && utils.IsGSTIN(invoice.SellerGSTIN):
+		// The first two digits of a GSTIN are its state code.
+		invoice.Interstate = invoice.BuyerGSTIN[:2] != invoice.SellerGSTIN[:2]
+	case options.PlaceOfSupply != "" && sellerState != "":
+		invoice.Interstate = !strings.EqualFold(strings.TrimSpace(options.PlaceOfSupply), strings.TrimSpace(sellerState))
+	}
+	invoice.PlaceOfSupply = options.PlaceOfSupply
+	switch {
+	case invoice.PlaceOfSupply != "":
+	case !invoice.Interstate:
+		invoice.PlaceOfSupply = sellerState
+	default:
+		invoice.PlaceOfSupply = "State code " + invoice.BuyerGSTIN[:2]
+	}
+
+	sacCode := s.sacCode
+	if options.SACCode != "" {
+		sacCode = options.SACCode
+	}
+
+	currency := requestCurrency(request)
+	zero, _ := models.NewMoney(0, currency.Code)
+
+	gstPercent := s.gstPercent
+	if pricing != nil {
+		gstPercent = pricing.GSTPercent
+		for _, category := range pricing.Categories {
+			code := sacCode
+			if categoryCode, ok := s.sacCodes[strings.ToLower(category.Name)]; ok && options.SACCode == "" {
+				code = categoryCode
+			}
+			invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+				Description: category.Name,
+				SACCode:     code,
+				Items:       category.Items,
+				Amount:      category.Amount,
+			})
+		}
+		invoice.Subtotal = pricing.Subtotal
+		invoice.Discount = pricing.Discount
+		invoice.Taxable = pricing.Taxable
+		invoice.Tax = pricing.GST
+		invoice.Total = pricing.Total
+		invoice.TCS = pricing.TCS
+	} else {
+		total, err := request.Payment.TotalAmount.Rescale(currency.Decimals)
+		if err != nil || !request.Payment.TotalAmount.Valid() {
+			total = zero
+		}
+		total = total.WithCurrency(currency.Code)
+
+		// The total is quoted inclusive of GST.
+		basisPoints := int64(math.Round(gstPercent * 100))
+		taxable := total.Ratio(10000, 10000+basisPoints)
+		invoice.Lines = []models.InvoiceLine{{
+			Description: fmt.Sprintf("Tour package: %s, %s (%d %s)", request.Trip.Title, request.Trip.Destination,
+				request.Trip.Travelers, pluralize(request.Trip.Travelers, "traveller")),
+			SACCode: sacCode,
+			Amount:  taxable,
+		}}
+		invoice.Subtotal = taxable
+		invoice.Discount = zero
+		invoice.Taxable = taxable
+		invoice.Tax = total.Sub(taxable)
+		invoice.Total = total
+
+		invoice.TCS = zero
+		if tcs, err := request.Payment.TCS.Rescale(currency.Decimals); err == nil && request.Payment.TCS.Valid() {
+			invoice.TCS = tcs.WithCurrency(currency.Code)
+		}
+	}
+	invoice.Payable = invoice.Total.Add(invoice.TCS)
+
+	if invoice.Interstate {
+		invoice.Taxes = []models.TaxLine{{Name: "IGST", Percent: gstPercent, Amount: invoice.Tax}}
+	} else {
+		central := invoice.Tax.Percent(50)
+		invoice.Taxes = []models.TaxLine{
+			{Name: "CGST", Percent: gstPercent / 2, Amount: central},
+			{Name: "SGST", Percent: gstPercent / 2, Amount: invoice.Tax.Sub(central)},
+		}
+	}
+
+	return invoice, nil
+}
+
+// Issue reserves the next invoice number of the financial year that now
+// falls in. A number is claimed by creating its record only if it does not
+// exist yet, so concurrent issues, in this process or another, never share
+// one; whoever loses a claim moves on to the next number.
+func (s *InvoiceService) Issue(ctx context.Context, request *models.ItineraryRequest, now time.Time) (string, error) {
+	year := financialYear(now)
+	last, err := s.lastIssued(ctx, year)
+	if err != nil {
+		return "", err
+	}
+
+	record := invoiceRecord{
+		IssuedAt:     now,
+		CustomerName: request.Customer.Name,
+		Destination:  request.Trip.Destination,
+	}
+	// The counter may be behind its records if it was lost or another
+	// replica has just issued, so claimed numbers are skipped.
+	for sequence := last + 1; ; sequence++ {
+		record.Sequence = sequence
+		record.Number = fmt.Sprintf("%s/%s/%0*d", s.prefix, year, s.digits, sequence)
+		data, err := json.MarshalIndent(record, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		err = s.storage.PutIfAbsent(ctx, s.recordKey(year, sequence), data, "application/json")
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return "", fmt.Errorf("failed to record invoice number: %w", err)
+		}
+	}
+
+	counter := invoiceCounter{FinancialYear: year, Last: record.Sequence, UpdatedAt: now}
+	if err := s.putJSON(ctx, s.counterKey(year), counter); err != nil {
+		// The record already claims the number, so the next issue skips it.
+		logrus.WithError(err).WithField("number", record.Number).Warn("Failed to update invoice counter")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"number":       record.Number,
+		"customerName": record.CustomerName,
+	}).Info("Invoice number issued")
+
+	return record.Number, nil
+}
+
+// lastIssued reads the counter of a financial year, or rebuilds it from the
+// year's records when it is missing.
+func (s *InvoiceService) lastIssued(ctx context.Context, year string) (int, error) {
+	data, err := readObject(ctx, s.storage, s.counterKey(year))
+	if err == nil {
+		var counter invoiceCounter
+		if err := json.Unmarshal(data, &counter); err == nil {
+			return counter.Last, nil
+		}
+		logrus.WithField("financialYear", year).Warn("Unreadable invoice counter, rebuilding it from issued invoices")
+	} else if !errors.Is(err, ErrObjectNotFound) {
+		return 0, fmt.Errorf("failed to read invoice counter: %w", err)
+	}
+
+	objects, err := s.storage.List(ctx, invoiceKeyPrefix+year+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list invoice numbers: %w", err)
+	}
+	last := 0
+	for _, object := range objects {
+		sequence, err := strconv.Atoi(strings.TrimSuffix(path.Base(object.Key), ".json"))
+		if err == nil && sequence > last {
+			last = sequence
+		}
+	}
+	return last, nil
+}
+
+func (s *InvoiceService) counterKey(year string) string {
+	return invoiceKeyPrefix + year + "/counter.json"
+}
+
+func (s *InvoiceService) recordKey(year string, sequence int) string {
+	return fmt.Sprintf("%s%s/%d.json", invoiceKeyPrefix, year, sequence)
+}
+
+func (s *InvoiceService) putJSON(ctx context.Context, key string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, key, data, "application/json")
+}
+
+// financialYear names the Indian financial year containing t, such as
+// "2026-27" for any date from April 2026 to March 2027.
+func financialYear(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+const (
+	testSellerGSTIN = "27AAPFU0939F1ZV" // Maharashtra
+	testBuyerGSTIN  = "29AAGCB7383J1Z4" // Karnataka
+)
+
+func TestFinancialYear(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2026-04-01", "2026-27"},
+		{"2027-03-31", "2026-27"},
+		{"2026-03-31", "2025-26"},
+		{"2099-12-31", "2099-00"},
+		{"2000-01-15", "1999-00"},
+	}
+
+	for _, tt := range tests {
+		date, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := financialYear(date); got != tt.want {
+			t.Errorf("financialYear(%s) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestInvoiceServiceIssue(t *testing.T) {
+	ctx := context.Background()
+	request := &models.ItineraryRequest{Customer: models.Customer{Name: "Rahul"}}
+	april := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	march := time.Date(2027, 3, 31, 10, 0, 0, 0, time.UTC)
+	nextYear := time.Date(2027, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, s *InvoiceService)
+		at    []time.Time
+		want  []string
+	}{
+		{
+			name: "numbers run in sequence within a year",
+			at:   []time.Time{april, march, nextYear},
+			want: []string{"PI/2026-27/0001", "PI/2026-27/0002", "PI/2027-28/0001"},
+		},
+		{
+			name: "claimed numbers are skipped",
+			setup: func(t *testing.T, s *InvoiceService) {
+				s.putJSON(ctx, s.recordKey("2026-27", 1), invoiceRecord{Sequence: 1})
+				s.putJSON(ctx, s.recordKey("2026-27", 2), invoiceRecord{Sequence: 2})
+			},
+			at:   []time.Time{april},
+			want: []string{"PI/2026-27/0003"},
+		},
+		{
+			name: "a lost counter is rebuilt from the records",
+			setup: func(t *testing.T, s *InvoiceService) {
+				s.putJSON(ctx, s.recordKey("2026-27", 7), invoiceRecord{Sequence: 7})
+				s.storage.Put(ctx, s.counterKey("2026-27"), []byte("not json"), "application/json")
+			},
+			at:   []time.Time{april},
+			want: []string{"PI/2026-27/0008"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &InvoiceService{storage: NewMemoryStorage(), prefix: "PI", digits: 4}
+			if tt.setup != nil {
+				tt.setup(t, s)
+			}
+
+			var got []string
+			for _, at := range tt.at {
+				number, err := s.Issue(ctx, request, at)
+				if err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+				got = append(got, number)
+			}
+			if !equalStrings(got, tt.want) {
+				t.Errorf("issued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvoiceServiceBuild(t *testing.T) {
+	issued := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	seller := models.CompanyInfo{RegisteredOffice: models.RegisteredOffice{State: "Maharashtra"}}
+
+	tests := []struct {
+		name        string
+		options     *models.InvoiceOptions
+		sellerGSTIN string
+		wantField   string
+		wantTaxes   []string
+		wantTaxable string
+		wantPlace   string
+	}{
+		{
+			name:      "seller GSTIN is required",
+			options:   &models.InvoiceOptions{},
+			wantField: "invoice.sellerGstin",
+		},
+		{
+			name:        "intrastate splits CGST and SGST",
+			sellerGSTIN: testSellerGSTIN,
+			wantTaxes:   []string{"CGST 2.5% 2500.00", "SGST 2.5% 2500.00"},
+			wantTaxable: "100000.00",
+			wantPlace:   "Maharashtra",
+		},
+		{
+			name:        "interstate by GSTIN charges IGST",
+			options:     &models.InvoiceOptions{SellerGSTIN: testSellerGSTIN, BuyerGSTIN: testBuyerGSTIN},
+			wantTaxes:   []string{"IGST 5% 5000.00"},
+			wantTaxable: "100000.00",
+			wantPlace:   "State code 29",
+		},
+		{
+			name:        "interstate by place of supply",
+			options:     &models.InvoiceOptions{PlaceOfSupply: "Goa"},
+			sellerGSTIN: testSellerGSTIN,
+			wantTaxes:   []string{"IGST 5% 5000.00"},
+			wantTaxable: "100000.00",
+			wantPlace:   "Goa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &InvoiceService{storage: NewMemoryStorage(), sellerGSTIN: tt.sellerGSTIN, gstPercent: 5, validityDays: 7}
+			request := &models.ItineraryRequest{
+				Currency: "INR",
+				Trip:     models.Trip{Title: "Trip", Destination: "Japan", Travelers: 2},
+				Payment:  models.Payment{TotalAmount: mustParseMoney(t, "105000")},
+				Invoice:  tt.options,
+			}
+
+			invoice, err := s.Build(request, nil, seller, "PI/2026-27/0001", issued)
+			if tt.wantField != "" {
+				var fe *fieldError
+				if !errors.As(err, &fe) || fe.field != tt.wantField {
+					t.Fatalf("Build() error = %v, want a fieldError on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			var taxes []string
+			for _, tax := range invoice.Taxes {
+				taxes = append(taxes, fmt.Sprintf("%s %g%% %s", tax.Name, tax.Percent, tax.Amount))
+			}
+			if !equalStrings(taxes, tt.wantTaxes) {
+				t.Errorf("taxes = %v, want %v", taxes, tt.wantTaxes)
+			}
+			if got := invoice.Taxable.String(); got != tt.wantTaxable {
+				t.Errorf("taxable = %s, want %s", got, tt.wantTaxable)
+			}
+			if invoice.PlaceOfSupply != tt.wantPlace {
+				t.Errorf("place of supply = %q, want %q", invoice.PlaceOfSupply, tt.wantPlace)
+			}
+			if invoice.ValidUntil != "2026-05-08" || invoice.Payable.String() != "105000.00" {
+				t.Errorf("valid until %s, payable %s", invoice.ValidUntil, invoice.Payable)
+			}
+		})
+	}
+}
+
+func TestInvoiceServiceIssueAcrossReplicas(t *testing.T) {
+	backends := []struct {
+		name string
+		new  func(t *testing.T) Storage
+	}{
+		{"local", func(t *testing.T) Storage { return NewLocalStorage(t.TempDir()) }},
+		{"memory", func(t *testing.T) Storage { return NewMemoryStorage() }},
+		{"s3", func(t *testing.T) Storage { return newFakeS3Storage(t, "") }},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := backend.new(t)
+			replicas := []*InvoiceService{
+				{storage: storage, prefix: "PI", digits: 4},
+				{storage: storage, prefix: "PI", digits: 4},
+			}
+			april := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+			const issues = 12
+			numbers := make(chan string, issues)
+			wins := raceWriters(issues, func(i int) error {
+				number, err := replicas[i%len(replicas)].Issue(ctx, &models.ItineraryRequest{}, april)
+				if err != nil {
+					t.Errorf("Issue() error = %v", err)
+					return err
+				}
+				numbers <- number
+				return nil
+			})
+			close(numbers)
+
+			if wins != issues {
+				t.Fatalf("%d of %d issues succeeded", wins, issues)
+			}
+			var got []string
+			for number := range numbers {
+				got = append(got, number)
+			}
+			sort.Strings(got)
+			var want []string
+			for i := 1; i <= issues; i++ {
+				want = append(want, fmt.Sprintf("PI/2026-27/%04d", i))
+			}
+			if !equalStrings(got, want) {
+				t.Errorf("issued %v, want each of %v once", got, want)
+			}
+		})
+	}
+}
+
+// issuedInvoices lists the invoice numbers claimed in a service's storage.
+func issuedInvoices(t *testing.T, s *PDFService) []string {
+	t.Helper()
+
+	objects, err := s.fileService.storage.List(context.Background(), invoiceKeyPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []string
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, "/counter.json") {
+			records = append(records, object.Key)
+		}
+	}
+	return records
+}
+
+func TestGenerateItineraryIssuesInvoiceNumberLast(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		sellerGSTIN string
+		closeQueue  bool
+		wantErr     bool
+	}{
+		{"failed build uses no number", "", false, true},
+		{"rejected render uses no number", testSellerGSTIN, true, true},
+		{"issued number replaces the placeholder", testSellerGSTIN, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPDFService(t)
+			if tt.closeQueue {
+				s.renderQueue.Close(ctx)
+			}
+			var printed []*renderDocument
+			s.print = func(ctx context.Context, doc *renderDocument, w io.Writer, captures ...*pageCapture) error {
+				printed = append(printed, doc)
+				return fakePrint(ctx, doc, w, captures...)
+			}
+			request := loadSample(t, "test_sample.json")
+			request.DocumentType = models.DocumentProformaInvoice
+			request.Invoice = &models.InvoiceOptions{SellerGSTIN: tt.sellerGSTIN}
+
+			response, err := s.GenerateItinerary(ctx, request)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GenerateItinerary() succeeded, want a failure")
+				}
+				if records := issuedInvoices(t, s); len(records) != 0 {
+					t.Errorf("a failed render claimed invoice numbers: %v", records)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateItinerary() error = %v", err)
+			}
+
+			want := fmt.Sprintf("PI/%s/0001", financialYear(time.Now()))
+			if response.InvoiceNumber != want {
+				t.Errorf("invoice number = %q, want %q", response.InvoiceNumber, want)
+			}
+			if len(printed) != 1 {
+				t.Fatalf("printed %d documents, want 1", len(printed))
+			}
+			html := printed[0].HTML
+			if !strings.Contains(html, want) || strings.Contains(html, "INVOICE-NUMBER-") {
+				t.Error("printed invoice does not carry its issued number in place of the placeholder")
+			}
+			if strings.Contains(html, `class="draft-badge"`) {
+				t.Error("issued invoice is marked as a draft")
+			}
+
+			// A document that already has its number keeps it.
+			if err := s.issueInvoiceNumber(ctx, request, printed[0]); err != nil || printed[0].InvoiceNumber != want {
+				t.Errorf("issueInvoiceNumber() again = %q, %v, want %q kept", printed[0].InvoiceNumber, err, want)
+			}
+			if records := issuedInvoices(t, s); len(records) != 1 {
+				t.Errorf("issued %d invoice numbers, want 1: %v", len(records), records)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrJobQueueFull = errors.New("job queue is full")
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobsShutdown = errors.New("job service is shutting down")
+	ErrJobNotReady  = errors.New("job has not finished")
+)
+
+// JobService renders itineraries in the background so clients are not held
+// on the connection for the whole Chrome render.
+type JobService struct {
+	pdfService     *PDFService
+	webhookService *WebhookService
+	queue          chan *pdfJob
+	retention      time.Duration
+	busyRetries    int
+	wg             sync.WaitGroup
+
+	// ctx is cancelled when Close gives up draining, which stops renders
+	// and retries still in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	jobs   map[string]*pdfJob
+}
+
+type pdfJob struct {
+	models.PDFJob
+	request     *models.ItineraryRequest
+	callbackURL string
+}
+
+func NewJobService(pdfService *PDFService, webhookService *WebhookService) *JobService {
+	cfg := config.AppConfig.Jobs
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+
+	if cfg.BusyRetries < 0 {
+		cfg.BusyRetries = 0
+	}
+
+	s := &JobService{
+		pdfService:     pdfService,
+		webhookService: webhookService,
+		queue:          make(chan *pdfJob, cfg.Capacity),
+		retention:      cfg.Retention,
+		busyRetries:    cfg.BusyRetries,
+		jobs:           make(map[string]*pdfJob),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	for i := 0; i < cfg.Workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+
+	return s
+}
+
+// Submit validates the request and queues it, returning the job immediately.
+// When callbackURL is set the result is also posted there once rendered; it
+// must pass WebhookService.CheckCallback.
+func (s *JobService) Submit(ctx context.Context, request *models.ItineraryRequest, callbackURL string) (*models.PDFJob, error) {
+	if err := s.pdfService.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+	if callbackURL != "" {
+		if err := s.webhookService.CheckCallback(ctx, callbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	job := &pdfJob{
+		PDFJob: models.PDFJob{
+			ID:          uuid.NewString(),
+			Status:      models.JobStatusQueued,
+			SubmittedAt: time.Now(),
+		},
+		request:     request,
+		callbackURL: callbackURL,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrJobsShutdown
+	}
+
+	s.pruneLocked()
+
+	select {
+	case s.queue <- job:
+	default:
+		return nil, ErrJobQueueFull
+	}
+	s.jobs[job.ID] = job
+
+	logrus.WithFields(logrus.Fields{
+		"jobID":        job.ID,
+		"customerName": request.Customer.Name,
+		"queued":       len(s.queue),
+	}).Info("PDF job queued")
+
+	snapshot := job.PDFJob
+	return &snapshot, nil
+}
+
+// Get returns a snapshot of the job so callers never race with the worker.
+func (s *JobService) Get(id string) (*models.PDFJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	snapshot := job.PDFJob
+	return &snapshot, nil
+}
+
+func (s *JobService) work() {
+	defer s.wg.Done()
+	for job := range s.queue {
+		s.run(job)
+	}
+}
+
+func (s *JobService) run(job *pdfJob) {
+	started := time.Now()
+	s.update(job, func(j *models.PDFJob) {
+		j.Status = models.JobStatusRendering
+		j.StartedAt = &started
+		j.QueuedMs = started.Sub(j.SubmittedAt).Milliseconds()
+	})
+
+	response, err := s.render(job)
+
+	finished := time.Now()
+	s.update(job, func(j *models.PDFJob) {
+		j.FinishedAt = &finished
+		j.RenderMs = finished.Sub(started).Milliseconds()
+		if err != nil {
+			j.Status = models.JobStatusFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = models.JobStatusDone
+		j.Result = response
+	})
+	job.request = nil
+
+	if job.callbackURL != "" {
+		s.webhookService.Dispatch(job.callbackURL, s.webhookPayload(job))
+	}
+
+	entry := logrus.WithFields(logrus.Fields{
+		"jobID":    job.ID,
+		"queuedMs": job.QueuedMs,
+		"renderMs": finished.Sub(started).Milliseconds(),
+	})
+	if err != nil {
+		entry.WithError(err).Error("PDF job failed")
+		return
+	}
+	entry.Info("PDF job completed")
+}
+
+// render generates the job's PDF. Synchronous requests share the render
+// queue, so a full queue is retried up to busyRetries times rather than
+// failing a job that was already accepted.
+func (s *JobService) render(job *pdfJob) (*models.PDFResponse, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := s.pdfService.GenerateItinerary(s.ctx, job.request)
+		if !errors.Is(err, ErrRenderQueueFull) {
+			return response, err
+		}
+		if attempt >= s.busyRetries {
+			return nil, fmt.Errorf("gave up after %d retries: %w", attempt, err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"jobID":   job.ID,
+			"attempt": attempt + 1,
+		}).Debug("Render queue full, retrying PDF job")
+
+		timer := time.NewTimer(s.pdfService.RetryAfter())
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %v", ErrJobsShutdown, s.ctx.Err())
+		}
+	}
+}
+
+func (s *JobService) webhookPayload(job *pdfJob) models.WebhookPayload {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	payload := models.WebhookPayload{
+		Event:       models.WebhookEventCompleted,
+		JobID:       job.ID,
+		Status:      job.Status,
+		Error:       job.Error,
+		SubmittedAt: job.SubmittedAt,
+		FinishedAt:  job.FinishedAt,
+		PDF:         job.Result,
+	}
+	if job.Status == models.JobStatusFailed {
+		payload.Event = models.WebhookEventFailed
+	}
+	return payload
+}
+
+func (s *JobService) update(job *pdfJob, fn func(j *models.PDFJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&job.PDFJob)
+}
+
+// pruneLocked forgets finished jobs older than the retention window.
+func (s *JobService) pruneLocked() {
+	if s.retention <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-s.retention)
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// Close stops accepting jobs and drains the ones already queued.
+func (s *JobService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	pending := len(s.queue)
+	close(s.queue)
+	s.mu.Unlock()
+
+	logrus.WithField("pending", pending).Info("Draining PDF jobs")
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return fmt.Errorf("PDF jobs did not drain: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+// newBusyJobService returns a job service whose render queue stays full
+// until the returned release func is called.
+func newBusyJobService(t *testing.T, retries int) (*JobService, func()) {
+	t.Helper()
+
+	pdfService := newTestPDFService(t)
+	pdfService.renderQueue.Close(context.Background())
+	pdfService.renderQueue = newTestRenderQueue(t, 1, 0)
+	pdfService.renderQueue.retryAfter = 5 * time.Millisecond
+
+	hold := make(chan struct{})
+	started := make(chan struct{})
+	hog := func(ctx context.Context) error {
+		close(started)
+		<-hold
+		return nil
+	}
+	// An unbuffered queue only accepts a job once its worker is waiting.
+	go func() {
+		for errors.Is(pdfService.renderQueue.Do(context.Background(), hog), ErrRenderQueueFull) {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	<-started
+
+	saved := config.AppConfig.Jobs
+	config.AppConfig.Jobs.Workers = 1
+	config.AppConfig.Jobs.BusyRetries = retries
+	s := NewJobService(pdfService, nil)
+	config.AppConfig.Jobs = saved
+
+	var once sync.Once
+	release := func() { once.Do(func() { close(hold) }) }
+	t.Cleanup(func() {
+		release()
+		s.Close(context.Background())
+	})
+	return s, release
+}
+
+func waitForJob(t *testing.T, s *JobService, id string) *models.PDFJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := s.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == models.JobStatusDone || job.Status == models.JobStatusFailed {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestJobServiceBusyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		releaseAfter time.Duration
+		wantStatus   string
+		wantInvoices int
+	}{
+		{"no retries", 0, 0, models.JobStatusFailed, 0},
+		{"retries exhausted", 3, 0, models.JobStatusFailed, 0},
+		{"queue frees up", 1000, 20 * time.Millisecond, models.JobStatusDone, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, release := newBusyJobService(t, tt.retries)
+			if tt.releaseAfter > 0 {
+				time.AfterFunc(tt.releaseAfter, release)
+			}
+
+			request := loadSample(t, "test_sample.json")
+			request.DocumentType = models.DocumentProformaInvoice
+			request.Invoice = &models.InvoiceOptions{SellerGSTIN: testSellerGSTIN}
+			submitted, err := s.Submit(context.Background(), request, "")
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+
+			job := waitForJob(t, s, submitted.ID)
+			// Retries turned away by the full queue never reach issuing.
+			if records := issuedInvoices(t, s.pdfService); len(records) != tt.wantInvoices {
+				t.Errorf("issued %d invoice numbers, want %d: %v", len(records), tt.wantInvoices, records)
+			}
+			if job.Status != tt.wantStatus {
+				t.Fatalf("Status = %q, want %q (error %q)", job.Status, tt.wantStatus, job.Error)
+			}
+			if tt.wantStatus == models.JobStatusFailed && job.Error == "" {
+				t.Error("failed job has no error")
+			}
+			if tt.wantStatus == models.JobStatusDone && job.Result == nil {
+				t.Error("done job has no result")
+			}
+		})
+	}
+}
+
+func TestJobServiceCloseStopsRetries(t *testing.T) {
+	s, _ := newBusyJobService(t, 1000000)
+
+	submitted, err := s.Submit(context.Background(), loadSample(t, "test_sample.json"), "")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	job := waitForJob(t, s, submitted.ID)
+	if job.Status != models.JobStatusFailed {
+		t.Errorf("Status = %q, want %q", job.Status, models.JobStatusFailed)
+	}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrLinksDisabled = errors.New("signed download links are not configured")
+	ErrLinkNotFound  = errors.New("download link not found")
+	ErrLinkInvalid   = errors.New("invalid download link signature")
+	ErrLinkExpired   = errors.New("download link has expired")
+	ErrLinkRevoked   = errors.New("download link has been revoked")
+	ErrLinkUsed      = errors.New("download link has already been used")
+	ErrLinkTTL       = errors.New("invalid download link expiry")
+)
+
+const linkKeyPrefix = "links/"
+
+// maxLinkUpdateAttempts bounds how often a link update is retried when
+// another writer changed the record first.
+const maxLinkUpdateAttempts = 5
+
+// LinkService mints HMAC-signed, expiring download links for stored PDFs.
+// Link records live in PDF storage next to the files, so revocation and
+// one-time use are shared by every instance using the same backend.
+type LinkService struct {
+	fileService *FileService
+	secret      []byte
+	defaultTTL  time.Duration
+	maxTTL      time.Duration
+}
+
+func NewLinkService(fileService *FileService) *LinkService {
+	cfg := config.AppConfig.Links
+	if cfg.Secret == "" {
+		logrus.Warn("links.secret is empty, signed download links are disabled")
+	}
+
+	return &LinkService{
+		fileService: fileService,
+		secret:      []byte(cfg.Secret),
+		defaultTTL:  cfg.DefaultTTL,
+		maxTTL:      cfg.MaxTTL,
+	}
+}
+
+// Create mints a link for a stored PDF. A zero ttl uses links.default_ttl.
+func (s *LinkService) Create(ctx context.Context, pdfID string, ttl time.Duration, oneTime bool) (*models.DownloadLink, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrLinksDisabled
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl <= 0 || (s.maxTTL > 0 && ttl > s.maxTTL) {
+		return nil, fmt.Errorf("%w: must be between 1s and %s", ErrLinkTTL, s.maxTTL)
+	}
+
+	if _, _, err := s.fileService.GetPDF(ctx, pdfID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	link := &models.DownloadLink{
+		ID:        uuid.NewString(),
+		PDFID:     pdfID,
+		OneTime:   oneTime,
+		CreatedAt: now,
+		// Expiry is signed with second precision.
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+
+	if err := s.save(ctx, link); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"linkID":    link.ID,
+		"pdfID":     pdfID,
+		"expiresAt": link.ExpiresAt,
+		"oneTime":   oneTime,
+	}).Info("Download link created")
+
+	return link, nil
+}
+
+// SignedPath returns the public path of a link, relative to the server root.
+func (s *LinkService) SignedPath(link *models.DownloadLink) string {
+	expires := strconv.FormatInt(link.ExpiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("pdf", link.PDFID)
+	query.Set("expires", expires)
+	if link.OneTime {
+		query.Set("once", "1")
+	}
+	query.Set("sig", s.sign(link.ID, link.PDFID, expires, link.OneTime))
+	return "/shared/" + link.ID + "?" + query.Encode()
+}
+
+// Redeem checks a link's signature, expiry and state and returns it. A
+// one-time link is marked used with a conditional write before it is
+// returned, so only one redemption wins even across replicas.
+func (s *LinkService) Redeem(ctx context.Context, linkID string, query url.Values) (*models.DownloadLink, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrLinksDisabled
+	}
+
+	pdfID := query.Get("pdf")
+	expires := query.Get("expires")
+	oneTime := query.Get("once") == "1"
+	expected := s.sign(linkID, pdfID, expires, oneTime)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		return nil, ErrLinkInvalid
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, ErrLinkInvalid
+	}
+	if time.Now().Unix() >= expiresAt {
+		return nil, ErrLinkExpired
+	}
+
+	link, err := s.update(ctx, linkID, func(link *models.DownloadLink) (bool, error) {
+		if link.PDFID != pdfID {
+			return false, ErrLinkInvalid
+		}
+		if link.RevokedAt != nil {
+			return false, ErrLinkRevoked
+		}
+		if !link.OneTime {
+			return false, nil
+		}
+		if link.UsedAt != nil {
+			return false, ErrLinkUsed
+		}
+		now := time.Now()
+		link.UsedAt = &now
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"linkID": link.ID,
+		"pdfID":  link.PDFID,
+	}).Info("Download link redeemed")
+
+	return link, nil
+}
+
+func (s *LinkService) Get(ctx context.Context, linkID string) (*models.DownloadLink, error) {
+	link, _, err := s.load(ctx, linkID)
+	return link, err
+}
+
+// Revoke stops a link from being redeemed before it expires.
+func (s *LinkService) Revoke(ctx context.Context, linkID string) (*models.DownloadLink, error) {
+	revoked := false
+	link, err := s.update(ctx, linkID, func(link *models.DownloadLink) (bool, error) {
+		revoked = link.RevokedAt == nil
+		if !revoked {
+			return false, nil
+		}
+		now := time.Now()
+		link.RevokedAt = &now
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		logrus.WithField("linkID", linkID).Info("Download link revoked")
+	}
+	return link, nil
+}
+
+// load reads a link record together with the ETag it was read at.
+func (s *LinkService) load(ctx context.Context, linkID string) (*models.DownloadLink, string, error) {
+	if _, err := uuid.Parse(linkID); err != nil {
+		return nil, "", ErrLinkNotFound
+	}
+
+	reader, info, err := s.fileService.storage.Get(ctx, s.key(linkID))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, "", ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read download link: %w", err)
+	}
+	defer reader.Close()
+
+	var link models.DownloadLink
+	if err := json.NewDecoder(reader).Decode(&link); err != nil {
+		return nil, "", fmt.Errorf("failed to decode download link: %w", err)
+	}
+	return &link, info.ETag, nil
+}
+
+// update applies change to a link and writes it back only if the record is
+// unchanged since it was read, retrying on a lost race. change reports
+// whether it modified the link; an error aborts the update.
+func (s *LinkService) update(ctx context.Context, linkID string, change func(link *models.DownloadLink) (bool, error)) (*models.DownloadLink, error) {
+	for attempt := 0; attempt < maxLinkUpdateAttempts; attempt++ {
+		link, etag, err := s.load(ctx, linkID)
+		if err != nil {
+			return nil, err
+		}
+		changed, err := change(link)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			return link, nil
+		}
+
+		data, err := json.MarshalIndent(link, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		err = s.fileService.storage.PutIfMatch(ctx, s.key(linkID), data, "application/json", etag)
+		switch {
+		case err == nil:
+			return link, nil
+		case errors.Is(err, ErrPreconditionFailed):
+			continue
+		case errors.Is(err, ErrObjectNotFound):
+			return nil, ErrLinkNotFound
+		default:
+			return nil, fmt.Errorf("failed to save download link: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("download link %s kept changing while being updated", linkID)
+}
+
+// PruneExpired deletes records of links that can no longer be redeemed.
+func (s *LinkService) PruneExpired(ctx context.Context) (int, error) {
+	objects, err := s.fileService.storage.List(ctx, linkKeyPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list download links: %w", err)
+	}
+
+	pruned := 0
+	for _, object := range objects {
+		linkID := strings.TrimSuffix(strings.TrimPrefix(object.Key, linkKeyPrefix), ".json")
+		link, err := s.Get(ctx, linkID)
+		if err != nil || time.Now().Before(link.ExpiresAt) {
+			continue
+		}
+		if err := s.fileService.storage.Delete(ctx, object.Key); err != nil {
+			logrus.WithError(err).WithField("linkID", linkID).Warn("Failed to prune download link")
+			continue
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+func (s *LinkService) sign(linkID, pdfID, expires string, oneTime bool) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{linkID, pdfID, expires, strconv.FormatBool(oneTime)}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LinkService) key(linkID string) string {
+	return linkKeyPrefix + linkID + ".json"
+}
+
+func (s *LinkService) save(ctx context.Context, link *models.DownloadLink) error {
+	data, err := json.MarshalIndent(link, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.fileService.storage.Put(ctx, s.key(link.ID), data, "application/json"); err != nil {
+		return fmt.Errorf("failed to save download link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+// newTestLinkServices returns n link services sharing one store, standing in
+// for replicas behind a load balancer.
+func newTestLinkServices(t *testing.T, n int) ([]*LinkService, string) {
+	t.Helper()
+
+	fileService, err := NewFileService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdfID := seedDocument(t, fileService, "Rahul", 16, 0)
+
+	saved := config.AppConfig.Links
+	config.AppConfig.Links = config.LinksConfig{Secret: "link-secret", DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour}
+	defer func() { config.AppConfig.Links = saved }()
+
+	services := make([]*LinkService, n)
+	for i := range services {
+		services[i] = NewLinkService(fileService)
+	}
+	return services, pdfID
+}
+
+func linkQuery(t *testing.T, s *LinkService, link *models.DownloadLink) url.Values {
+	t.Helper()
+
+	u, err := url.Parse(s.SignedPath(link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query()
+}
+
+func TestLinkServiceRedeem(t *testing.T) {
+	tests := []struct {
+		name     string
+		oneTime  bool
+		wantWins int
+	}{
+		{"one-time link served once across replicas", true, 1},
+		{"reusable link served every time", false, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			replicas, pdfID := newTestLinkServices(t, 2)
+			link, err := replicas[0].Create(ctx, pdfID, 0, tt.oneTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := linkQuery(t, replicas[0], link)
+
+			var wg sync.WaitGroup
+			results := make(chan error, 8)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(s *LinkService) {
+					defer wg.Done()
+					_, err := s.Redeem(ctx, link.ID, query)
+					results <- err
+				}(replicas[i%len(replicas)])
+			}
+			wg.Wait()
+			close(results)
+
+			wins := 0
+			for err := range results {
+				switch {
+				case err == nil:
+					wins++
+				case !errors.Is(err, ErrLinkUsed):
+					t.Errorf("Redeem() error = %v, want nil or %v", err, ErrLinkUsed)
+				}
+			}
+			if wins != tt.wantWins {
+				t.Errorf("%d redemptions succeeded, want %d", wins, tt.wantWins)
+			}
+
+			stored, err := replicas[1].Get(ctx, link.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (stored.UsedAt != nil) != tt.oneTime {
+				t.Errorf("UsedAt = %v, want it set only for one-time links", stored.UsedAt)
+			}
+		})
+	}
+}
+
+func TestLinkServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+	replicas, pdfID := newTestLinkServices(t, 2)
+	link, err := replicas[0].Create(ctx, pdfID, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := replicas[1].Revoke(ctx, link.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("Revoke() = %+v, %v", revoked, err)
+	}
+	again, err := replicas[0].Revoke(ctx, link.ID)
+	if err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("second Revoke() = %+v, %v, want the first revocation kept", again, err)
+	}
+
+	if _, err := replicas[0].Redeem(ctx, link.ID, linkQuery(t, replicas[0], link)); !errors.Is(err, ErrLinkRevoked) {
+		t.Errorf("Redeem() of a revoked link error = %v, want %v", err, ErrLinkRevoked)
+	}
+	if _, err := replicas[0].Revoke(ctx, "1b4e28ba-2fa1-11d2-883f-0016d3cca427"); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("Revoke() of an unknown link error = %v, want %v", err, ErrLinkNotFound)
+	}
+}
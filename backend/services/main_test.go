@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		os.Exit(1)
+	}
+	logrus.SetLevel(logrus.ErrorLevel)
+
+	workspace, err := os.MkdirTemp("", "vigovia-services-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create workspace:", err)
+		os.Exit(1)
+	}
+
+	cfg := config.AppConfig
+	cfg.Server.TemplateDir = filepath.Join("..", "templates")
+	cfg.PDF.StorageBackend = StorageBackendMemory
+	cfg.PDF.StoragePath = filepath.Join(workspace, "pdfs")
+	cfg.PDF.WorkspacePath = filepath.Join(workspace, "render")
+
+	code := m.Run()
+	os.RemoveAll(workspace)
+	os.Exit(code)
+}
+
+// newTestPDFService builds a PDFService on in-memory storage whose printer
+// writes the rendered HTML instead of driving Chrome.
+func newTestPDFService(t *testing.T) *PDFService {
+	t.Helper()
+
+	fileService, err := NewFileService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &PDFService{
+		templateService: NewTemplateService(),
+		fileService:     fileService,
+		renderQueue:     NewRenderQueue(),
+		ruleEngine:      NewRuleEngine(),
+		pricing:         NewPricingEngine(),
+		payments:        NewPaymentScheduler(),
+		invoices:        NewInvoiceService(fileService),
+	}
+	s.print = fakePrint
+	t.Cleanup(func() { s.renderQueue.Close(context.Background()) })
+	return s
+}
+
+func fakePrint(ctx context.Context, doc *renderDocument, w io.Writer, captures ...*pageCapture) error {
+	for _, capture := range captures {
+		capture.images = append(capture.images, []byte("image"))
+	}
+	if w == nil {
+		return nil
+	}
+	_, err := io.WriteString(w, "%PDF-1.4\n"+doc.HTML)
+	return err
+}
+
+// loadSample reads a request from test_samples.
+func loadSample(t *testing.T, name string) *models.ItineraryRequest {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("..", "test_samples", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request models.ItineraryRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return &request
+}
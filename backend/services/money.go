@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+// moneyField is an amount in the request with its JSON path.
+type moneyField struct {
+	path  string
+	value models.Money
+}
+
+// requestCurrency resolves the currency every amount in the request is in:
+// the request's own, then the pdf.currency default.
+func requestCurrency(request *models.ItineraryRequest) *utils.Currency {
+	defaults := config.AppConfig.PDF
+	return utils.CurrencyOrDefault(
+		firstNonEmpty(request.Currency, defaults.Currency),
+		firstNonEmpty(request.Locale, defaults.Locale),
+	)
+}
+
+// moneyFields lists every amount in the request that was given.
+func moneyFields(request *models.ItineraryRequest) []moneyField {
+	var fields []moneyField
+	add := func(path string, value models.Money) {
+		if value.IsSet() {
+			fields = append(fields, moneyField{path, value})
+		}
+	}
+
+	add("payment.totalAmount", request.Payment.TotalAmount)
+	add("payment.tcs", request.Payment.TCS)
+	for i, rule := range request.Payment.Schedule {
+		add(fmt.Sprintf("payment.schedule[%d].amount", i), rule.Amount)
+	}
+	for i, installment := range request.Payment.Installments {
+		add(fmt.Sprintf("payment.installments[%d].amount", i), installment.Amount)
+	}
+	if request.Pricing != nil {
+		add("pricing.discount", request.Pricing.Discount)
+		for i, item := range request.Pricing.Items {
+			add(fmt.Sprintf("pricing.items[%d].price", i), item.Price)
+		}
+	}
+	for i, flight := range request.Flights {
+		add(fmt.Sprintf("flights[%d].price", i), flight.Price)
+	}
+	for i, hotel := range request.Hotels {
+		add(fmt.Sprintf("hotels[%d].pricePerNight", i), hotel.PricePerNight)
+	}
+	for i, day := range request.Itinerary.Days {
+		for j, activity := range day.Activities {
+			add(fmt.Sprintf("itinerary.days[%d].activities[%d].price", i, j), activity.Price)
+		}
+		for j, transfer := range day.Transfers {
+			add(fmt.Sprintf("itinerary.days[%d].transfers[%d].price", i, j), transfer.Price)
+		}
+		for j, flight := range day.Flights {
+			add(fmt.Sprintf("itinerary.days[%d].flights[%d].price", i, j), flight.Price)
+		}
+	}
+	return fields
+}
+
+// checkMoney reports amounts that cannot be added to the rest of the
+// request: ones in another currency, and ones with more decimal places than
+// the currency has, such as 1500.5 yen. Unreadable amounts are left to the
+// "money" validation tag.
+func checkMoney(request *models.ItineraryRequest) []models.APIError {
+	currency := requestCurrency(request)
+
+	var problems []models.APIError
+	for _, field := range moneyFields(request) {
+		if !field.value.Valid() {
+			continue
+		}
+		if code := field.value.Currency(); code != "" && code != currency.Code {
+			problems = append(problems, models.APIError{
+				Field:   field.path,
+				Message: fmt.Sprintf("Currency %s does not match the request currency %s", code, currency.Code),
+				Code:    "CURRENCY_MISMATCH",
+			})
+			continue
+		}
+		if _, err := field.value.Rescale(currency.Decimals); err != nil {
+			problems = append(problems, models.APIError{
+				Field:   field.path,
+				Message: fmt.Sprintf("%s allows at most %d decimal places", currency.Code, currency.Decimals),
+				Code:    "INVALID_AMOUNT",
+			})
+		}
+	}
+	return problems
+}
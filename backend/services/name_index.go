@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Every readable name has an index object listing the documents saved under
+// it, so saving a new version never has to list the whole store.
+const nameIndexPrefix = "names/"
+
+// nameIndexMarker records that indexes and metadata have been built for the
+// documents stored before indexes existed.
+const nameIndexMarker = nameIndexPrefix + "indexed.json"
+
+type nameIndex struct {
+	BaseName  string            `json:"baseName"`
+	Documents []indexedDocument `json:"documents"`
+}
+
+type indexedDocument struct {
+	ID       string `json:"id"`
+	FileName string `json:"fileName"`
+	Version  int    `json:"version"`
+}
+
+// latest returns the document with the highest version.
+func (idx *nameIndex) latest() (indexedDocument, bool) {
+	var latest indexedDocument
+	for _, doc := range idx.Documents {
+		if doc.Version > latest.Version {
+			latest = doc
+		}
+	}
+	return latest, latest.ID != ""
+}
+
+// put adds doc, replacing an entry with the same ID.
+func (idx *nameIndex) put(doc indexedDocument) {
+	idx.remove(doc.ID)
+	idx.Documents = append(idx.Documents, doc)
+}
+
+func (idx *nameIndex) remove(id string) {
+	kept := idx.Documents[:0]
+	for _, doc := range idx.Documents {
+		if doc.ID != id {
+			kept = append(kept, doc)
+		}
+	}
+	idx.Documents = kept
+}
+
+func (s *FileService) nameIndexKey(baseName string) string {
+	sum := sha256.Sum256([]byte(baseName))
+	return nameIndexPrefix + hex.EncodeToString(sum[:16]) + ".json"
+}
+
+func (s *FileService) readNameIndex(ctx context.Context, baseName string) (*nameIndex, error) {
+	data, err := readObject(ctx, s.storage, s.nameIndexKey(baseName))
+	if errors.Is(err, ErrObjectNotFound) {
+		return &nameIndex{BaseName: baseName}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read name index: %w", err)
+	}
+
+	var idx nameIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to decode name index: %w", err)
+	}
+	return &idx, nil
+}
+
+// writeNameIndex stores idx, or removes it once it lists no documents.
+func (s *FileService) writeNameIndex(ctx context.Context, idx *nameIndex) error {
+	key := s.nameIndexKey(idx.BaseName)
+	if len(idx.Documents) == 0 {
+		return s.storage.Delete(ctx, key)
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, key, data, "application/json")
+}
+
+// ensureIndexed builds name indexes, and metadata where it is missing, for
+// documents stored before indexes existed. It scans storage at most once per
+// store and must be called with saveMu held.
+func (s *FileService) ensureIndexed(ctx context.Context) error {
+	if s.indexed {
+		return nil
+	}
+	if _, err := s.storage.Stat(ctx, nameIndexMarker); err == nil {
+		s.indexed = true
+		return nil
+	} else if !errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("failed to check name indexes: %w", err)
+	}
+
+	infos, err := s.scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexes := make(map[string]*nameIndex)
+	for i := range infos {
+		info := &infos[i]
+		if _, err := s.readMetadata(ctx, info.ID); err != nil {
+			if err := s.writeMetadata(ctx, info); err != nil {
+				return fmt.Errorf("failed to write PDF metadata: %w", err)
+			}
+		}
+
+		idx, ok := indexes[info.BaseName]
+		if !ok {
+			idx = &nameIndex{BaseName: info.BaseName}
+			indexes[info.BaseName] = idx
+		}
+		idx.put(indexedDocument{ID: info.ID, FileName: info.FileName, Version: info.Version})
+	}
+	for _, idx := range indexes {
+		if err := s.writeNameIndex(ctx, idx); err != nil {
+			return fmt.Errorf("failed to write name index: %w", err)
+		}
+	}
+
+	marker, _ := json.Marshal(map[string]time.Time{"indexedAt": time.Now()})
+	if err := s.storage.Put(ctx, nameIndexMarker, marker, "application/json"); err != nil {
+		return fmt.Errorf("failed to record name indexes: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"documents": len(infos),
+		"names":     len(indexes),
+	}).Info("Indexed stored PDFs by name")
+
+	s.indexed = true
+	return nil
+}
+
+// unindex drops a deleted document from its name index.
+func (s *FileService) unindex(ctx context.Context, info *models.FileInfoResponse) {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	idx, err := s.readNameIndex(ctx, info.BaseName)
+	if err == nil {
+		idx.remove(info.ID)
+		err = s.writeNameIndex(ctx, idx)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("id", info.ID).Warn("Failed to update name index")
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidImageOptions = errors.New("invalid image options")
+	ErrNoDocumentSource    = errors.New("pdf was stored without its source document")
+)
+
+// cssPixelsPerInch is the fixed CSS reference resolution Chrome prints at.
+const cssPixelsPerInch = 96
+
+const (
+	imageFormatPNG  = "png"
+	imageFormatJPEG = "jpeg"
+)
+
+// pageCapture asks a render for screenshots of its printed pages. Pages are
+// cut from the print-media layout at the printable width, so breaks follow
+// the page height rather than CSS break rules and can differ slightly from
+// the PDF.
+type pageCapture struct {
+	cover    bool
+	format   string
+	dpi      int
+	quality  int
+	maxPages int
+	images   [][]byte
+}
+
+// resolveImageOptions fills request options from pdf.images. It returns nil
+// when no page images were requested.
+func resolveImageOptions(opts *models.ImageOptions) (*pageCapture, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	cfg := config.AppConfig.PDF.Images
+	capture := &pageCapture{
+		format:   firstNonEmpty(opts.Format, cfg.Format),
+		dpi:      opts.DPI,
+		quality:  opts.Quality,
+		maxPages: cfg.MaxPages,
+	}
+	if capture.dpi == 0 {
+		capture.dpi = cfg.DPI
+	}
+	if capture.quality == 0 {
+		capture.quality = cfg.Quality
+	}
+
+	if capture.format != imageFormatPNG && capture.format != imageFormatJPEG {
+		return nil, &fieldError{"config.images.format", fmt.Errorf("%w: unknown format %q, expected png or jpeg", ErrInvalidImageOptions, capture.format)}
+	}
+	if capture.dpi < 24 || (cfg.MaxDPI > 0 && capture.dpi > cfg.MaxDPI) {
+		return nil, &fieldError{"config.images.dpi", fmt.Errorf("%w: dpi must be between 24 and %d", ErrInvalidImageOptions, cfg.MaxDPI)}
+	}
+	if capture.quality < 1 || capture.quality > 100 {
+		return nil, &fieldError{"config.images.quality", fmt.Errorf("%w: quality must be between 1 and 100", ErrInvalidImageOptions)}
+	}
+
+	return capture, nil
+}
+
+// coverCapture returns the first-page thumbnail capture, or nil when
+// pdf.images.cover_thumbnail is off.
+func coverCapture() *pageCapture {
+	cfg := config.AppConfig.PDF.Images
+	if !cfg.CoverThumbnail {
+		return nil
+	}
+
+	capture, err := resolveImageOptions(&models.ImageOptions{DPI: cfg.CoverDPI})
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid cover thumbnail settings, skipping thumbnail")
+		return nil
+	}
+	capture.cover = true
+	capture.maxPages = 1
+	return capture
+}
+
+func (p *pageCapture) extension() string {
+	if p.format == imageFormatJPEG {
+		return "jpg"
+	}
+	return "png"
+}
+
+// capture screenshots the loaded document page by page. It must run in the
+// tab that rendered the document, after it is ready.
+func (p *pageCapture) capture(ctx context.Context, layout *pageLayout) error {
+	width, pageHeight := layout.printableSize()
+	width *= cssPixelsPerInch
+	pageHeight *= cssPixelsPerInch
+	scale := float64(p.dpi) / cssPixelsPerInch
+
+	if err := emulation.SetEmulatedMedia().WithMedia("print").Do(ctx); err != nil {
+		return fmt.Errorf("failed to emulate print media: %w", err)
+	}
+	err := emulation.SetDeviceMetricsOverride(int64(math.Ceil(width)), int64(math.Ceil(pageHeight)), scale, false).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to set page viewport: %w", err)
+	}
+	defer emulation.ClearDeviceMetricsOverride().Do(ctx)
+
+	_, _, _, _, _, content, err := page.GetLayoutMetrics().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to measure document: %w", err)
+	}
+
+	pages := int(math.Ceil(content.Height / pageHeight))
+	if pages < 1 {
+		pages = 1
+	}
+	if p.maxPages > 0 && pages > p.maxPages {
+		pages = p.maxPages
+	}
+
+	format := page.CaptureScreenshotFormatPng
+	if p.format == imageFormatJPEG {
+		format = page.CaptureScreenshotFormatJpeg
+	}
+
+	p.images = nil
+	for i := 0; i < pages; i++ {
+		shot := page.CaptureScreenshot().
+			WithFormat(format).
+			WithFromSurface(true).
+			WithCaptureBeyondViewport(true).
+			WithClip(&page.Viewport{
+				X:      0,
+				Y:      float64(i) * pageHeight,
+				Width:  width,
+				Height: pageHeight,
+				Scale:  1,
+			})
+		if format == page.CaptureScreenshotFormatJpeg {
+			shot = shot.WithQuality(int64(p.quality))
+		}
+
+		data, err := shot.Do(ctx)
+		if err != nil {
+			p.images = nil
+			return fmt.Errorf("failed to capture page %d: %w", i+1, err)
+		}
+		p.images = append(p.images, data)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pages":  pages,
+		"format": p.format,
+		"dpi":    p.dpi,
+		"cover":  p.cover,
+	}).Info("Page images captured")
+
+	return nil
+}
+
+// PageImages returns page images of a stored PDF, rendering them from the
+// stored source document the first time a format and DPI are requested.
+func (s *PDFService) PageImages(ctx context.Context, id string, opts models.ImageOptions) (*models.PageImagesResponse, error) {
+	capture, err := resolveImageOptions(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	info, _, err := s.fileService.GetPDF(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PageImagesResponse{
+		PDFID:  info.ID,
+		Format: capture.format,
+		DPI:    capture.dpi,
+	}
+
+	images, err := s.fileService.findPageImages(ctx, info.ID, capture)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) > 0 {
+		response.Pages = images
+		return response, nil
+	}
+
+	source, err := s.fileService.readSource(ctx, info.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.renderQueue.Do(ctx, func(ctx context.Context) error {
+		return s.print(ctx, source, nil, capture)
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("id", info.ID).Error("Failed to render page images")
+		return nil, err
+	}
+
+	response.Pages, err = s.fileService.savePageImages(ctx, info.ID, capture)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+var ErrInvalidPageLayout = errors.New("invalid page layout")
+
+// pageLayout is the resolved paper size and margins for a render, in inches
+type pageLayout struct {
+	Width        float64
+	Height       float64
+	Landscape    bool
+	MarginTop    float64
+	MarginBottom float64
+	MarginLeft   float64
+	MarginRight  float64
+}
+
+// resolvePageLayout applies request-level overrides on top of the server
+// defaults from the pdf section of config.yaml.
+func resolvePageLayout(requestConfig models.PDFConfig) (*pageLayout, error) {
+	defaults := config.AppConfig.PDF
+
+	format := firstNonEmpty(requestConfig.PageFormat, defaults.PageFormat)
+	size, err := utils.ParsePaperSize(format)
+	if err != nil {
+		return nil, &fieldError{"config.pageFormat", fmt.Errorf("%w: %v", ErrInvalidPageLayout, err)}
+	}
+
+	orientation := strings.ToLower(firstNonEmpty(requestConfig.Orientation, defaults.Orientation))
+	if orientation != "portrait" && orientation != "landscape" {
+		return nil, &fieldError{"config.orientation", fmt.Errorf("%w: unknown orientation %q, expected portrait or landscape", ErrInvalidPageLayout, orientation)}
+	}
+
+	layout := &pageLayout{
+		Width:     size.Width,
+		Height:    size.Height,
+		Landscape: orientation == "landscape",
+	}
+
+	margins := []struct {
+		name     string
+		value    string
+		fallback string
+		target   *float64
+	}{
+		{"top", requestConfig.Margin.Top, defaults.DefaultMargin.Top, &layout.MarginTop},
+		{"bottom", requestConfig.Margin.Bottom, defaults.DefaultMargin.Bottom, &layout.MarginBottom},
+		{"left", requestConfig.Margin.Left, defaults.DefaultMargin.Left, &layout.MarginLeft},
+		{"right", requestConfig.Margin.Right, defaults.DefaultMargin.Right, &layout.MarginRight},
+	}
+	for _, margin := range margins {
+		value, err := utils.ParseLength(firstNonEmpty(margin.value, margin.fallback))
+		if err != nil {
+			return nil, &fieldError{"config.margin." + margin.name, fmt.Errorf("%w: invalid %s margin: %v", ErrInvalidPageLayout, margin.name, err)}
+		}
+		*margin.target = value
+	}
+
+	if printableWidth, printableHeight := layout.printableSize(); printableWidth <= 0 || printableHeight <= 0 {
+		return nil, &fieldError{"config.margin", fmt.Errorf("%w: margins leave no printable area on %s %s", ErrInvalidPageLayout, format, orientation)}
+	}
+
+	return layout, nil
+}
+
+// pageSize returns the paper size in inches as printed, turned for landscape.
+func (l *pageLayout) pageSize() (width, height float64) {
+	if l.Landscape {
+		return l.Height, l.Width
+	}
+	return l.Width, l.Height
+}
+
+// printableSize returns the area inside the margins in inches, turned for
+// landscape.
+func (l *pageLayout) printableSize() (width, height float64) {
+	width, height = l.pageSize()
+	return width - l.MarginLeft - l.MarginRight, height - l.MarginTop - l.MarginBottom
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestResolvePageLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    models.PDFConfig
+		wantField string
+		wantWidth float64
+	}{
+		{"defaults", models.PDFConfig{}, "", 8.27},
+		{"custom size", models.PDFConfig{PageFormat: "254mmx508mm"}, "", 10},
+		{"landscape", models.PDFConfig{PageFormat: "Letter", Orientation: "landscape"}, "", 8.5},
+		{"unknown format", models.PDFConfig{PageFormat: "B5"}, "config.pageFormat", 0},
+		{"infinite size", models.PDFConfig{PageFormat: "infx10"}, "config.pageFormat", 0},
+		{"unknown orientation", models.PDFConfig{Orientation: "sideways"}, "config.orientation", 0},
+		{"NaN margin", models.PDFConfig{Margin: models.PageMargin{Top: "NaN"}}, "config.margin.top", 0},
+		{"infinite margin", models.PDFConfig{Margin: models.PageMargin{Left: "Infmm"}}, "config.margin.left", 0},
+		{"negative margin", models.PDFConfig{Margin: models.PageMargin{Right: "-1in"}}, "config.margin.right", 0},
+		{"no printable area", models.PDFConfig{Margin: models.PageMargin{Left: "5in", Right: "5in"}}, "config.margin", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := resolvePageLayout(tt.config)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("resolvePageLayout() error = %v", err)
+				}
+				if layout.Width != tt.wantWidth {
+					t.Errorf("Width = %v, want %v", layout.Width, tt.wantWidth)
+				}
+				return
+			}
+
+			var fe *fieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("resolvePageLayout() error = %v, want a fieldError", err)
+			}
+			if fe.field != tt.wantField {
+				t.Errorf("field = %q, want %q", fe.field, tt.wantField)
+			}
+			if !errors.Is(err, ErrInvalidPageLayout) {
+				t.Errorf("error %v does not wrap ErrInvalidPageLayout", err)
+			}
+		})
+	}
+}
+
+func TestPageLayoutPrintableSize(t *testing.T) {
+	tests := []struct {
+		name                   string
+		landscape              bool
+		wantPageW, wantPageH   float64
+		wantPrintW, wantPrintH float64
+	}{
+		{"portrait", false, 8.5, 11, 7, 10},
+		{"landscape", true, 11, 8.5, 9.5, 7.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &pageLayout{Width: 8.5, Height: 11, Landscape: tt.landscape, MarginTop: 0.5, MarginBottom: 0.5, MarginLeft: 0.75, MarginRight: 0.75}
+
+			if w, h := layout.pageSize(); w != tt.wantPageW || h != tt.wantPageH {
+				t.Errorf("pageSize() = %v x %v, want %v x %v", w, h, tt.wantPageW, tt.wantPageH)
+			}
+			if w, h := layout.printableSize(); w != tt.wantPrintW || h != tt.wantPrintH {
+				t.Errorf("printableSize() = %v x %v, want %v x %v", w, h, tt.wantPrintW, tt.wantPrintH)
+			}
+		})
+	}
+}
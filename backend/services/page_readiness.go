@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/sirupsen/logrus"
+)
+
+const readinessPollInterval = 50 * time.Millisecond
+
+// waitForAssetsScript resolves once web fonts are ready and every <img> has
+// been decoded, returning the sources of images that failed to load.
+const waitForAssetsScript = `(async () => {
+	await document.fonts.ready;
+	const images = Array.from(document.images);
+	await Promise.all(images.map((img) => img.decode().catch(() => {})));
+	return images
+		.filter((img) => !img.complete || img.naturalWidth === 0)
+		.map((img) => img.currentSrc || img.src || img.outerHTML);
+})()`
+
+// pageReadiness tracks network activity on a tab so a render can start as
+// soon as the page has settled instead of after a fixed delay.
+type pageReadiness struct {
+	mu           sync.Mutex
+	inflight     map[network.RequestID]string
+	failed       map[string]string
+	lastActivity time.Time
+}
+
+func newPageReadiness() *pageReadiness {
+	return &pageReadiness{
+		inflight:     make(map[network.RequestID]string),
+		failed:       make(map[string]string),
+		lastActivity: time.Now(),
+	}
+}
+
+// listen must be registered before navigation so no request is missed.
+func (r *pageReadiness) listen(ctx context.Context) {
+	chromedp.ListenTarget(ctx, r.handle)
+}
+
+// handle records a single target event.
+func (r *pageReadiness) handle(ev interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		r.inflight[e.RequestID] = e.Request.URL
+	case *network.EventLoadingFinished:
+		delete(r.inflight, e.RequestID)
+	case *network.EventLoadingFailed:
+		if url, ok := r.inflight[e.RequestID]; ok && !e.Canceled {
+			r.failed[url] = e.ErrorText
+		}
+		delete(r.inflight, e.RequestID)
+	default:
+		return
+	}
+	r.lastActivity = time.Now()
+}
+
+func (r *pageReadiness) networkIdle(idle time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.inflight) == 0 && time.Since(r.lastActivity) >= idle
+}
+
+func (r *pageReadiness) pendingURLs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	urls := make([]string, 0, len(r.inflight))
+	for _, url := range r.inflight {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func (r *pageReadiness) hasFailed(url string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, failed := r.failed[url]
+	return failed
+}
+
+func (r *pageReadiness) failedAssets() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	assets := make([]string, 0, len(r.failed))
+	for url, reason := range r.failed {
+		assets = append(assets, fmt.Sprintf("%s (%s)", url, reason))
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// missingAssets combines failed network requests with images the page
+// reported as undecoded, listing each asset once.
+func (r *pageReadiness) missingAssets(brokenImages []string) []string {
+	missing := r.failedAssets()
+	for _, src := range brokenImages {
+		if !r.hasFailed(src) {
+			missing = append(missing, fmt.Sprintf("%s (image did not decode)", src))
+		}
+	}
+	return missing
+}
+
+// checkMissingAssets fails the render when assets are missing and
+// chromedp.readiness.fail_on_missing_assets is set, otherwise it warns.
+func checkMissingAssets(missing []string, failOnMissing bool) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	if failOnMissing {
+		return fmt.Errorf("assets failed to load: %s", strings.Join(missing, ", "))
+	}
+	logrus.WithField("assets", missing).Warn("Rendering with assets that failed to load")
+	return nil
+}
+
+// waitUntilReady blocks until the network is idle, every image is decoded
+// and fonts are loaded, bounded by chromedp.readiness.timeout.
+func (r *pageReadiness) waitUntilReady() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		cfg := config.AppConfig.ChromeDP.Readiness
+		started := time.Now()
+
+		readyCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+
+		ticker := time.NewTicker(readinessPollInterval)
+		defer ticker.Stop()
+
+		for !r.networkIdle(cfg.NetworkIdle) {
+			select {
+			case <-readyCtx.Done():
+				return fmt.Errorf("page not ready after %s, assets still loading: %s",
+					cfg.Timeout, strings.Join(r.pendingURLs(), ", "))
+			case <-ticker.C:
+			}
+		}
+
+		var brokenImages []string
+		err := chromedp.Evaluate(waitForAssetsScript, &brokenImages, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}).Do(readyCtx)
+		if err != nil {
+			if readyCtx.Err() != nil {
+				return fmt.Errorf("page not ready after %s, waiting for images and fonts timed out", cfg.Timeout)
+			}
+			return fmt.Errorf("failed to check page assets: %w", err)
+		}
+
+		missing := r.missingAssets(brokenImages)
+
+		logrus.WithFields(logrus.Fields{
+			"waited":        time.Since(started).String(),
+			"missingAssets": len(missing),
+		}).Debug("Page readiness reached")
+
+		return checkMissingAssets(missing, cfg.FailOnMissingAssets)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func requestSent(id, url string) *network.EventRequestWillBeSent {
+	return &network.EventRequestWillBeSent{
+		RequestID: network.RequestID(id),
+		Request:   &network.Request{URL: url},
+	}
+}
+
+func loadingFinished(id string) *network.EventLoadingFinished {
+	return &network.EventLoadingFinished{RequestID: network.RequestID(id)}
+}
+
+func loadingFailed(id, reason string, canceled bool) *network.EventLoadingFailed {
+	return &network.EventLoadingFailed{RequestID: network.RequestID(id), ErrorText: reason, Canceled: canceled}
+}
+
+func TestPageReadinessNetworkIdle(t *testing.T) {
+	tests := []struct {
+		name        string
+		events      []interface{}
+		idle        time.Duration
+		wantIdle    bool
+		wantPending []string
+	}{
+		{
+			name:     "no requests",
+			idle:     0,
+			wantIdle: true,
+		},
+		{
+			name:        "request in flight",
+			events:      []interface{}{requestSent("1", "https://cdn.example.com/logo.png")},
+			idle:        0,
+			wantIdle:    false,
+			wantPending: []string{"https://cdn.example.com/logo.png"},
+		},
+		{
+			name: "all requests settled",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/logo.png"),
+				requestSent("2", "https://fonts.example.com/font.woff2"),
+				loadingFinished("1"),
+				loadingFailed("2", "net::ERR_NAME_NOT_RESOLVED", false),
+			},
+			idle:     0,
+			wantIdle: true,
+		},
+		{
+			name: "one of two still loading",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/b.png"),
+				requestSent("2", "https://cdn.example.com/a.png"),
+				loadingFinished("1"),
+			},
+			idle:        0,
+			wantIdle:    false,
+			wantPending: []string{"https://cdn.example.com/a.png"},
+		},
+		{
+			name: "idle window not yet elapsed",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/logo.png"),
+				loadingFinished("1"),
+			},
+			idle:     time.Hour,
+			wantIdle: false,
+		},
+		{
+			name:     "unrelated events ignored",
+			events:   []interface{}{&network.EventDataReceived{RequestID: "9"}, "not an event"},
+			idle:     0,
+			wantIdle: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPageReadiness()
+			for _, ev := range tt.events {
+				r.handle(ev)
+			}
+
+			if got := r.networkIdle(tt.idle); got != tt.wantIdle {
+				t.Errorf("networkIdle(%s) = %v, want %v", tt.idle, got, tt.wantIdle)
+			}
+			if got := r.pendingURLs(); !equalStrings(got, tt.wantPending) {
+				t.Errorf("pendingURLs() = %v, want %v", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestPageReadinessFailedAssets(t *testing.T) {
+	tests := []struct {
+		name         string
+		events       []interface{}
+		brokenImages []string
+		want         []string
+	}{
+		{
+			name: "nothing failed",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/logo.png"),
+				loadingFinished("1"),
+			},
+		},
+		{
+			name: "failed requests sorted by url",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/z.png"),
+				requestSent("2", "https://cdn.example.com/a.png"),
+				loadingFailed("1", "net::ERR_CONNECTION_REFUSED", false),
+				loadingFailed("2", "net::ERR_NAME_NOT_RESOLVED", false),
+			},
+			want: []string{
+				"https://cdn.example.com/a.png (net::ERR_NAME_NOT_RESOLVED)",
+				"https://cdn.example.com/z.png (net::ERR_CONNECTION_REFUSED)",
+			},
+		},
+		{
+			name: "canceled request is not a failure",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/logo.png"),
+				loadingFailed("1", "net::ERR_ABORTED", true),
+			},
+		},
+		{
+			name:   "failure for an unknown request ignored",
+			events: []interface{}{loadingFailed("7", "net::ERR_FAILED", false)},
+		},
+		{
+			name:         "undecoded image reported",
+			brokenImages: []string{"https://cdn.example.com/corrupt.jpg"},
+			want:         []string{"https://cdn.example.com/corrupt.jpg (image did not decode)"},
+		},
+		{
+			name: "failed image listed once",
+			events: []interface{}{
+				requestSent("1", "https://cdn.example.com/logo.png"),
+				loadingFailed("1", "net::ERR_CONNECTION_REFUSED", false),
+			},
+			brokenImages: []string{"https://cdn.example.com/logo.png"},
+			want:         []string{"https://cdn.example.com/logo.png (net::ERR_CONNECTION_REFUSED)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPageReadiness()
+			for _, ev := range tt.events {
+				r.handle(ev)
+			}
+
+			if got := r.missingAssets(tt.brokenImages); !equalStrings(got, tt.want) {
+				t.Errorf("missingAssets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMissingAssets(t *testing.T) {
+	missing := []string{
+		"https://cdn.example.com/a.png (net::ERR_NAME_NOT_RESOLVED)",
+		"https://cdn.example.com/b.jpg (image did not decode)",
+	}
+
+	tests := []struct {
+		name          string
+		missing       []string
+		failOnMissing bool
+		wantErr       string
+	}{
+		{"nothing missing", nil, true, ""},
+		{"missing but tolerated", missing, false, ""},
+		{
+			"missing and fatal", missing, true,
+			"assets failed to load: https://cdn.example.com/a.png (net::ERR_NAME_NOT_RESOLVED), https://cdn.example.com/b.jpg (image did not decode)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMissingAssets(tt.missing, tt.failOnMissing)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkMissingAssets() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkMissingAssets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,47 +16,209 @@ import (
 	"github.com/KrishKoria/Vigovia/config"
 	"github.com/KrishKoria/Vigovia/models"
 	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/chromedp/cdproto/cdp"
+	cdpio "github.com/chromedp/cdproto/io"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// pdfStreamChunkSize is how much of the PDF is read from Chrome per call.
+const pdfStreamChunkSize = 256 * 1024
+
 type PDFService struct {
 	templateService *TemplateService
 	fileService     *FileService
+	browserPool     *BrowserPool
+	renderQueue     *RenderQueue
+	ruleEngine      *RuleEngine
+	pricing         *PricingEngine
+	payments        *PaymentScheduler
+	invoices        *InvoiceService
+	
+	// print turns a document into a PDF and page images; it is
+	// convertHTMLToPDF outside tests, which need no browser.
+	print func(ctx context.Context, doc *renderDocument, w io.Writer, captures ...*pageCapture) error
 }
 
-func NewPDFService() *PDFService {
-	return &PDFService{
+func NewPDFService(fileService *FileService) *PDFService {
+	s := &PDFService{
 		templateService: NewTemplateService(),
-		fileService:     NewFileService(),
+		fileService:     fileService,
+		browserPool:     NewBrowserPool(),
+		renderQueue:     NewRenderQueue(),
+		ruleEngine:      NewRuleEngine(),
+		pricing:         NewPricingEngine(),
+		payments:        NewPaymentScheduler(),
+		invoices:        NewInvoiceService(fileService),
 	}
+	s.print = s.convertHTMLToPDF
+	return s
+}
+
+func (s *PDFService) PoolStats() models.BrowserPoolStats {
+	return s.browserPool.Stats()
 }
 
-func (s *PDFService) GenerateItinerary(request *models.ItineraryRequest) (*models.PDFResponse, error) {
+func (s *PDFService) QueueStats() models.RenderQueueStats {
+	return s.renderQueue.Stats()
+}
+
+func (s *PDFService) RetryAfter() time.Duration {
+	return s.renderQueue.RetryAfter()
+}
+
+func (s *PDFService) Close(ctx context.Context) error {
+	if err := s.renderQueue.Close(ctx); err != nil {
+		return err
+	}
+	return s.browserPool.Close(ctx)
+}
+
+// ValidateRequest runs every check that does not need a browser, so callers
+// can reject a request before queueing it. Failures are a *ValidationError.
+func (s *PDFService) ValidateRequest(request *models.ItineraryRequest) error {
+	if problems, warnings := s.Validate(request); len(problems) > 0 {
+		return &ValidationError{Errors: problems, Warnings: warnings}
+	}
+	
+	return nil
+}
+
+func (s *PDFService) GenerateItinerary(ctx context.Context, request *models.ItineraryRequest) (*models.PDFResponse, error) {
 	logrus.Info("Starting PDF generation")
 	
-	if errors := utils.ValidateStruct(request); len(errors) > 0 {
-		return nil, fmt.Errorf("validation failed: %v", errors)
+	doc, err := s.prepareDocument(ctx, request)
+	if err != nil {
+		return nil, err
 	}
 	
-	templateData := s.transformToTemplateData(request)
+	captures, err := s.requestedCaptures(request)
+	if err != nil {
+		return nil, err
+	}
+	
+	var pdfData bytes.Buffer
+	err = s.renderQueue.Do(ctx, func(ctx context.Context) error {
+		if err := s.issueInvoiceNumber(ctx, request, doc); err != nil {
+			return err
+		}
+		return s.print(ctx, doc, &pdfData, captures...)
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to convert HTML to PDF")
+		return nil, err 
+	}
 	
 	logrus.WithFields(logrus.Fields{
-		"customerName": templateData.Customer.Name,
-		"daysCount": len(templateData.Days),
-		"flightsCount": len(templateData.Flights),
-		"hotelsCount": len(templateData.Hotels),
-		"hasPayment": templateData.Payment.TotalAmount != "",
-	}).Info("Template data prepared")
+		"pdfSize": pdfData.Len(),
+		"htmlSize": len(doc.HTML),
+	}).Info("HTML converted to PDF")
 	
-	html, err := s.templateService.RenderTemplate("base.html", templateData)
+	return s.savePDF(ctx, request, doc, pdfData.Bytes(), captures)
+}
+
+// ErrPDFNotStored reports that a streamed PDF reached the client in full
+// but could not be saved.
+var ErrPDFNotStored = errors.New("pdf streamed but not stored")
+
+// PDFSink receives a streamed PDF. Start is called with the download file
+// name before rendering begins; the first Write marks the point after which
+// errors can no longer be reported to the client as a normal response.
+type PDFSink interface {
+	io.Writer
+	Start(filename string)
+}
+
+// StreamItinerary renders a PDF straight into sink as Chrome produces it.
+// With store set the document is also saved, with any requested page images,
+// and described by the returned response; otherwise nothing is persisted,
+// page images are skipped and the response only carries the file name and
+// size.
+func (s *PDFService) StreamItinerary(ctx context.Context, request *models.ItineraryRequest, sink PDFSink, store bool) (*models.PDFResponse, error) {
+	logrus.WithField("store", store).Info("Starting streamed PDF generation")
+	
+	doc, err := s.prepareDocument(ctx, request)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to render template")
-		return nil, fmt.Errorf("failed to render template: %w", err)
+		return nil, err
+	}
+	
+	var captures []*pageCapture
+	if store {
+		captures, err = s.requestedCaptures(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	var stored bytes.Buffer
+	out := &countingWriter{w: sink}
+	var w io.Writer = out
+	if store {
+		w = io.MultiWriter(out, &stored)
 	}
 	
-	html = s.convertStaticURLsToFilePaths(html)
+	var filename string
+	err = s.renderQueue.Do(ctx, func(ctx context.Context) error {
+		if err := s.issueInvoiceNumber(ctx, request, doc); err != nil {
+			return err
+		}
+		// An invoice's file name carries its number.
+		filename = s.generateFilename(request, doc)
+		sink.Start(filename)
+		return s.print(ctx, doc, w, captures...)
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("bytesSent", out.n).Error("Failed to stream PDF")
+		return nil, err
+	}
+	
+	if !store {
+		logrus.WithFields(logrus.Fields{
+			"fileName": filename,
+			"pdfSize": out.n,
+		}).Info("PDF streamed without storing")
+		
+		return &models.PDFResponse{
+			FileName:      filename,
+			FileSize:      utils.FormatFileSize(out.n),
+			GeneratedAt:   time.Now(),
+			InvoiceNumber: doc.InvoiceNumber,
+		}, nil
+	}
+	
+	response, err := s.savePDF(ctx, request, doc, stored.Bytes(), captures)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPDFNotStored, err)
+	}
+	return response, nil
+}
+
+// renderDocument is a rendered itinerary or invoice ready to print. It is
+// stored with each PDF so page images can be produced later.
+type renderDocument struct {
+	HTML          string      `json:"html"`
+	Layout        *pageLayout `json:"layout"`
+	InvoiceNumber string      `json:"invoiceNumber,omitempty"`
+	
+	// placeholder stands in for a proforma invoice's number in HTML until
+	// issueInvoiceNumber replaces it.
+	placeholder string
+}
+
+// prepareDocument renders a request into a printable document. A proforma
+// invoice is rendered with a placeholder number; see issueInvoiceNumber.
+func (s *PDFService) prepareDocument(ctx context.Context, request *models.ItineraryRequest) (*renderDocument, error) {
+	var placeholder string
+	if request.DocumentType == models.DocumentProformaInvoice {
+		placeholder = "INVOICE-NUMBER-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+	
+	html, layout, err := s.renderHTML(request, placeholder)
+	if err != nil {
+		return nil, err
+	}
 	
 	htmlPreview := html
 	if len(html) > 200 {
@@ -62,135 +229,342 @@ func (s *PDFService) GenerateItinerary(request *models.ItineraryRequest) (*model
 		"htmlPreview": htmlPreview,
 	}).Info("Template rendered to HTML")
 	
-	pdfData, err := s.convertHTMLToPDF(html)
+	return &renderDocument{HTML: html, Layout: layout, placeholder: placeholder}, nil
+}
+
+// issueInvoiceNumber gives a proforma invoice its number in place of the
+// placeholder. It runs in the render queue right before printing, so a
+// request that is invalid or turned away by a full queue never uses a
+// number up, and it does nothing once the document has one.
+func (s *PDFService) issueInvoiceNumber(ctx context.Context, request *models.ItineraryRequest, doc *renderDocument) error {
+	if doc.placeholder == "" || doc.InvoiceNumber != "" {
+		return nil
+	}
+	
+	number, err := s.invoices.Issue(ctx, request, time.Now())
 	if err != nil {
-		logrus.WithError(err).Error("Failed to convert HTML to PDF")
-		return nil, err 
+		logrus.WithError(err).Error("Failed to issue invoice number")
+		return err
+	}
+	doc.HTML = strings.ReplaceAll(doc.HTML, doc.placeholder, template.HTMLEscapeString(number))
+	doc.InvoiceNumber = number
+	return nil
+}
+
+// requestedCaptures lists the page images to take during a render: the
+// cover thumbnail, if enabled, and the pages asked for in config.images.
+func (s *PDFService) requestedCaptures(request *models.ItineraryRequest) ([]*pageCapture, error) {
+	var captures []*pageCapture
+	if cover := coverCapture(); cover != nil {
+		captures = append(captures, cover)
+	}
+	
+	pages, err := resolveImageOptions(request.Config.Images)
+	if err != nil {
+		return nil, err
+	}
+	if pages != nil {
+		captures = append(captures, pages)
+	}
+	return captures, nil
+}
+
+// renderHTML validates a request and renders base.html for it, or
+// invoice.html for a proforma invoice, which is marked as a draft when
+// invoiceNumber is empty. Static asset URLs are left as /static/ paths.
+func (s *PDFService) renderHTML(request *models.ItineraryRequest, invoiceNumber string) (string, *pageLayout, error) {
+	if err := s.ValidateRequest(request); err != nil {
+		return "", nil, err
+	}
+	
+	request, _ = deriveFields(request)
+	
+	pricing, _, err := s.pricing.Apply(request)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.payments.Apply(request); err != nil {
+		return "", nil, err
+	}
+	
+	layout, err := resolvePageLayout(request.Config)
+	if err != nil {
+		return "", nil, err
+	}
+	
+	templateData := s.transformToTemplateData(request)
+	templateData.Pricing = pricing
+	templateData.Sections.Pricing = templateData.Sections.Pricing && pricing != nil
+	
+	templateName := "base.html"
+	if request.DocumentType == models.DocumentProformaInvoice {
+		templateData.Invoice, err = s.invoices.Build(request, pricing, templateData.CompanyInfo, invoiceNumber, time.Now())
+		if err != nil {
+			return "", nil, err
+		}
+		templateName = "invoice.html"
 	}
 	
 	logrus.WithFields(logrus.Fields{
-		"pdfSize": len(pdfData),
-		"htmlSize": len(html),
-	}).Info("HTML converted to PDF")
+		"customerName": templateData.Customer.Name,
+		"daysCount": len(templateData.Days),
+		"flightsCount": len(templateData.Flights),
+		"hotelsCount": len(templateData.Hotels),
+		"hasPayment": !templateData.Payment.TotalAmount.IsZero(),
+		"template": templateName,
+	}).Info("Template data prepared")
 	
-	k := s.generateFilename(request)
+	html, err := s.templateService.RenderTemplate(templateName, templateData)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to render template")
+		return "", nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	
+	return html, layout, nil
+}
+
+func (s *PDFService) savePDF(ctx context.Context, request *models.ItineraryRequest, doc *renderDocument, pdfData []byte, captures []*pageCapture) (*models.PDFResponse, error) {
+	k := s.generateFilename(request, doc)
+	
+	fileInfo := &models.FileInfoResponse{
+		CustomerName:  request.Customer.Name,
+		CustomerEmail: request.Customer.Email,
+		Destination:   request.Trip.Destination,
+		StartDate:     request.Trip.StartDate,
+		EndDate:       request.Trip.EndDate,
+		Travelers:     request.Trip.Travelers,
+		DocumentType:  request.DocumentType,
+		InvoiceNumber: doc.InvoiceNumber,
+	}
 	
-	filePath, err := s.fileService.SavePDF(pdfData, k)
+	storageKey, err := s.fileService.SavePDF(ctx, pdfData, k, request.Config.StorageMode, fileInfo)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to save PDF")
 		return nil, fmt.Errorf("failed to save PDF: %w", err)
 	}
 	
-	fileSize, err := utils.GetFileSize(filePath)
-	if err != nil {
-		logrus.WithError(err).Warn("Failed to get file size")
-		fileSize = "Unknown"
-	}
+	fileSize := utils.FormatFileSize(fileInfo.FileSize)
 	
 	response := &models.PDFResponse{
-		FilePath:    filePath,
-		FileName:    k,
-		FileSize:    fileSize,
-		GeneratedAt: time.Now(),
+		ID:            fileInfo.ID,
+		StorageKey:    storageKey,
+		FileName:      fileInfo.FileName,
+		ContentHash:   fileInfo.ContentHash,
+		Version:       fileInfo.Version,
+		FileSize:      fileSize,
+		GeneratedAt:   time.Now(),
+		InvoiceNumber: doc.InvoiceNumber,
+	}
+	
+	// Validation passed before rendering, so only warnings remain.
+	_, response.Warnings = s.Validate(request)
+	
+	// The PDF is what was asked for; a missing source or image only limits
+	// what can be derived from it later.
+	if err := s.fileService.saveSource(ctx, fileInfo.ID, doc); err != nil {
+		logrus.WithError(err).WithField("id", fileInfo.ID).Warn("Failed to store PDF source document")
+	}
+	for _, capture := range captures {
+		images, err := s.fileService.savePageImages(ctx, fileInfo.ID, capture)
+		if err != nil {
+			logrus.WithError(err).WithField("id", fileInfo.ID).Warn("Failed to store page images")
+			continue
+		}
+		if capture.cover {
+			response.CoverThumbnail = &images[0]
+			fileInfo.CoverThumbnail = &images[0]
+			if err := s.fileService.writeMetadata(ctx, fileInfo); err != nil {
+				logrus.WithError(err).WithField("id", fileInfo.ID).Warn("Failed to update PDF metadata")
+			}
+		} else {
+			response.Pages = images
+		}
 	}
 	
 	logrus.WithFields(logrus.Fields{
 		"k": k,
 		"fileSize": fileSize,
-		"filePath": filePath,
+		"storageKey": storageKey,
 	}).Info("PDF generated successfully")
 	
 	return response, nil
 }
 
-func (s *PDFService) convertHTMLToPDF(html string) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), config.AppConfig.ChromeDP.Timeout)
-	defer cancel()
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.DisableGPU,
-		chromedp.NoDefaultBrowserCheck,
-		chromedp.Flag("headless", config.AppConfig.ChromeDP.Headless),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-web-security", config.AppConfig.ChromeDP.DisableWebSecurity),
-		chromedp.Flag("disable-features", "VizDisplayCompositor"),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-plugins", true),
-		chromedp.Flag("disable-background-timer-throttling", true),
-		chromedp.Flag("disable-backgrounding-occluded-windows", true),
-		chromedp.Flag("disable-renderer-backgrounding", true),
-		chromedp.Flag("force-color-profile", "srgb"),
-		chromedp.Flag("enable-print-background", true),
-	)
-	
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
+// convertHTMLToPDF prints a document and copies the PDF into w. Chrome hands
+// the document over as a stream, so large PDFs are read in chunks. Page
+// images are captured in the same tab afterwards; with a nil w only the
+// captures run.
+func (s *PDFService) convertHTMLToPDF(ctx context.Context, doc *renderDocument, w io.Writer, captures ...*pageCapture) error {
+	layout := doc.Layout
+	html := s.convertStaticURLsToFilePaths(doc.HTML)
+	
+	tab, err := s.browserPool.Acquire(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to acquire browser from pool")
+		return fmt.Errorf("failed to acquire browser: %w", err)
+	}
+	defer tab.Release()
 	
-	chromeCtx, cancel := chromedp.NewContext(allocCtx)
+	chromeCtx, cancel := context.WithTimeout(tab.Ctx, config.AppConfig.ChromeDP.Timeout)
 	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
 	
 	logrus.Info("Starting ChromeDP HTML to PDF conversion")
 	
-	var pdfBuffer []byte
 	var pageTitle string
 	var bodyText string
 	
-	tempHTMLFile := filepath.Join(config.AppConfig.PDF.StoragePath, "temp_render.html")
-	err := os.WriteFile(tempHTMLFile, []byte(html), 0644)
+	workspace, err := s.createRenderWorkspace()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workspace)
+	
+	tempHTMLFile := filepath.Join(workspace, "index.html")
+	err = os.WriteFile(tempHTMLFile, []byte(html), 0644)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to write temporary HTML file")
-		return nil, fmt.Errorf("failed to write temporary HTML file: %w", err)
+		return fmt.Errorf("failed to write temporary HTML file: %w", err)
 	}
-	defer os.Remove(tempHTMLFile) 
 	
 	absPath, err := filepath.Abs(tempHTMLFile)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get absolute path")
-		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 	
 	fileURL := "file:///" + filepath.ToSlash(absPath)
 	logrus.WithField("fileURL", fileURL).Info("Loading HTML from file")
 	
+	readiness := newPageReadiness()
+	readiness.listen(chromeCtx)
+	
 	err = chromedp.Run(chromeCtx,
 		chromedp.Navigate(fileURL),
 		chromedp.WaitReady("body", chromedp.ByQuery),
 		chromedp.Title(&pageTitle),
 		chromedp.Text("body", &bodyText, chromedp.ByQuery),
-		chromedp.Sleep(3*time.Second), 
+		readiness.waitUntilReady(),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			buf, _, err := page.PrintToPDF().
-				WithPaperWidth(8.27).  
-				WithPaperHeight(11.7). 
-				WithMarginTop(0.4).
-				WithMarginBottom(0.4).
-				WithMarginLeft(0.4).
-				WithMarginRight(0.4).
+			if w == nil {
+				return nil
+			}
+			_, stream, err := page.PrintToPDF().
+				WithPaperWidth(layout.Width).
+				WithPaperHeight(layout.Height).
+				WithLandscape(layout.Landscape).
+				WithMarginTop(layout.MarginTop).
+				WithMarginBottom(layout.MarginBottom).
+				WithMarginLeft(layout.MarginLeft).
+				WithMarginRight(layout.MarginRight).
 				WithPrintBackground(true).
 				WithPreferCSSPageSize(false).
 				WithDisplayHeaderFooter(false).
+				WithTransferMode(page.PrintToPDFTransferModeReturnAsStream).
 				Do(ctx)
 			if err != nil {
 				logrus.WithError(err).Error("PDF generation failed in ChromeDP")
 				return err
 			}
-			pdfBuffer = buf
-			logrus.WithField("pdfSize", len(buf)).Info("PDF generated successfully in ChromeDP")
+			written, err := copyPDFStream(ctx, stream, w)
+			if err != nil {
+				logrus.WithError(err).WithField("bytesWritten", written).Error("Failed to read PDF stream from ChromeDP")
+				return err
+			}
+			logrus.WithField("pdfSize", written).Info("PDF generated successfully in ChromeDP")
+			return nil
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			for _, capture := range captures {
+				err := capture.capture(ctx, layout)
+				if err != nil && w == nil {
+					return err
+				}
+				if err != nil {
+					// The PDF is already written; it is still returned without images.
+					logrus.WithError(err).Warn("Failed to capture page images")
+				}
+			}
 			return nil
 		}),
 	)
 	
 	if err != nil {
 		logrus.WithError(err).Error("ChromeDP execution failed")
-		return nil, fmt.Errorf("chromedp error: %w", err)
+		return fmt.Errorf("chromedp error: %w", err)
 	}
 
-	return pdfBuffer, nil
+	return nil
 }
 
+// copyPDFStream drains a CDP stream handle into w chunk by chunk.
+func copyPDFStream(ctx context.Context, handle cdpio.StreamHandle, w io.Writer) (int64, error) {
+	defer cdpio.Close(handle).Do(ctx)
+	
+	var written int64
+	for {
+		var res cdpio.ReadReturns
+		err := cdp.Execute(ctx, cdpio.CommandRead, cdpio.Read(handle).WithSize(pdfStreamChunkSize), &res)
+		if err != nil {
+			return written, fmt.Errorf("failed to read PDF stream: %w", err)
+		}
+		
+		chunk := []byte(res.Data)
+		if res.Base64encoded {
+			chunk, err = base64.StdEncoding.DecodeString(res.Data)
+			if err != nil {
+				return written, fmt.Errorf("failed to decode PDF stream: %w", err)
+			}
+		}
+		
+		if len(chunk) > 0 {
+			n, err := w.Write(chunk)
+			written += int64(n)
+			if err != nil {
+				return written, fmt.Errorf("failed to write PDF stream: %w", err)
+			}
+		}
+		
+		if res.EOF {
+			return written, nil
+		}
+	}
+}
+
+// countingWriter counts the bytes passed through to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// createRenderWorkspace gives each render its own directory so concurrent
+// requests never read or delete each other's HTML.
+func (s *PDFService) createRenderWorkspace() (string, error) {
+	root := config.AppConfig.PDF.WorkspacePath
+	if err := utils.EnsureDirectory(root); err != nil {
+		logrus.WithError(err).Error("Failed to create render workspace root")
+		return "", fmt.Errorf("failed to create render workspace root: %w", err)
+	}
+	
+	workspace, err := os.MkdirTemp(root, "render-")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create render workspace")
+		return "", fmt.Errorf("failed to create render workspace: %w", err)
+	}
+	
+	return workspace, nil
+}
 
 func (s *PDFService) transformToTemplateData(request *models.ItineraryRequest) *models.TemplateData {
+	currency := requestCurrency(request)
+	
 	importantNotes := request.ImportantNotes
 	if len(importantNotes) == 0 {
 		importantNotes = []models.ImportantNote{
@@ -231,20 +605,7 @@ func (s *PDFService) transformToTemplateData(request *models.ItineraryRequest) *
 	
 	companyInfo := request.CompanyInfo
 	if companyInfo.Name == "" {
-		companyInfo = models.CompanyInfo{
-			Name: "Vigovia Tech Pvt. Ltd",
-			RegisteredOffice: models.RegisteredOffice{
-				Address: "Hd-109 Cinnabar Hills, Links Business Park",
-				City:    "Karnataka",
-				State:   "Karnataka",
-				Country: "India",
-			},
-			Contact: models.ContactInfo{
-				Phone: "+91-99X9999999",
-				Email: "Contact@Vigovia.Com",
-			},
-			Logo: "/static/final-logo-2.png",
-		}
+		companyInfo = vigoviaCompany
 	}
 	
 	return &models.TemplateData{
@@ -255,16 +616,42 @@ func (s *PDFService) transformToTemplateData(request *models.ItineraryRequest) *
 		Hotels:         request.Hotels,
 		Payment:        enhancedPayment,
 		Config:         request.Config,
+		Sections:       s.resolveSections(request.Config),
+		Theme:          buildTheme(request.Config.CustomBranding, companyInfo),
 		ImportantNotes: importantNotes,
 		ScopeOfService: scopeOfService,
 		Inclusions:     inclusions,
 		VisaDetails:    visaDetails,
 		CompanyInfo:    companyInfo,
+		Currency:       currency.Code,
+		Locale:         currency.Locale,
 		GeneratedAt:    time.Now(),
 	}
 }
 
-func (s *PDFService) generateFilename(request *models.ItineraryRequest) string {
+func (s *PDFService) resolveSections(cfg models.PDFConfig) models.SectionToggles {
+	include := func(flag *bool) bool {
+		return flag == nil || *flag
+	}
+	
+	return models.SectionToggles{
+		Flights:        include(cfg.IncludeFlights),
+		Hotels:         include(cfg.IncludeHotels),
+		Activities:     include(cfg.IncludeActivities),
+		Payments:       include(cfg.IncludePayments),
+		ImportantNotes: include(cfg.IncludeImportantNotes),
+		Scope:          include(cfg.IncludeScope),
+		Inclusions:     include(cfg.IncludeInclusions),
+		VisaDetails:    include(cfg.IncludeVisaDetails),
+		Pricing:        include(cfg.IncludePricing),
+	}
+}
+
+func (s *PDFService) generateFilename(request *models.ItineraryRequest, doc *renderDocument) string {
+	if doc.InvoiceNumber != "" {
+		return utils.GenerateInvoiceFilename(doc.InvoiceNumber, request.Customer.Name)
+	}
+	
 	baseFilename := utils.GenerateReadableFilename(
 		request.Trip.Destination,
 		request.Trip.StartDate,
@@ -293,24 +680,7 @@ func (s *PDFService) countTotalTransfers(days []models.Day) int {
 }
 
 func (s *PDFService) enhancePaymentData(payment models.Payment) models.Payment {
-	enhanced := payment
-	enhanced.Status = "Pending" 
-	
-	var advanceAmount, balanceAmount string
-	
-	for _, installment := range payment.Installments {
-		switch installment.InstallmentName {
-		case "Advance Payment":
-			advanceAmount = installment.Amount
-		case "Balance Payment":
-			balanceAmount = installment.Amount
-		}
-	}
-	
-	enhanced.AdvanceAmount = advanceAmount
-	enhanced.BalanceAmount = balanceAmount
-	
-	return enhanced
+	return trackPayment(payment, time.Now())
 }
 
 func (s *PDFService) convertStaticURLsToFilePaths(html string) string {
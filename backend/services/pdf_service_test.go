@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestGenerateItineraryConcurrent(t *testing.T) {
+	const renders = 40
+	s := newTestPDFService(t)
+	// Room for every render, so only the worker count limits them.
+	s.renderQueue.Close(context.Background())
+	s.renderQueue = newTestRenderQueue(t, 2, renders)
+
+	var active, peak atomic.Int32
+	s.print = func(ctx context.Context, doc *renderDocument, w io.Writer, captures ...*pageCapture) error {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return fakePrint(ctx, doc, w, captures...)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := make(map[string]bool)
+	versions := make(map[int]bool)
+	for i := 0; i < renders; i++ {
+		request := loadSample(t, "test_sample.json")
+		request.Trip.Title = fmt.Sprintf("Race Trip %d", i)
+		request.Config.StorageMode = StorageModeVersion
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := s.GenerateItinerary(context.Background(), request)
+			if err != nil {
+				t.Errorf("GenerateItinerary() error = %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if ids[response.ID] {
+				t.Errorf("duplicate ID %s", response.ID)
+			}
+			if versions[response.Version] {
+				t.Errorf("duplicate version %d", response.Version)
+			}
+			ids[response.ID] = true
+			versions[response.Version] = true
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != renders {
+		t.Errorf("stored %d PDFs, want %d", len(ids), renders)
+	}
+	if got, limit := peak.Load(), int32(s.renderQueue.workers); got > limit {
+		t.Errorf("peak concurrent prints = %d, want at most %d", got, limit)
+	}
+}
+
+func TestResolveSections(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   models.SectionToggles
+	}{
+		{"omitted", `{}`, models.SectionToggles{
+			Flights: true, Hotels: true, Activities: true, Payments: true, ImportantNotes: true,
+			Scope: true, Inclusions: true, VisaDetails: true, Pricing: true,
+		}},
+		{"some hidden", `{"includeFlights": false, "includeHotels": true, "includePricing": false}`, models.SectionToggles{
+			Flights: false, Hotels: true, Activities: true, Payments: true, ImportantNotes: true,
+			Scope: true, Inclusions: true, VisaDetails: true, Pricing: false,
+		}},
+		{"null keeps default", `{"includeScope": null}`, models.SectionToggles{
+			Flights: true, Hotels: true, Activities: true, Payments: true, ImportantNotes: true,
+			Scope: true, Inclusions: true, VisaDetails: true, Pricing: true,
+		}},
+	}
+
+	s := &PDFService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg models.PDFConfig
+			if err := json.Unmarshal([]byte(tt.config), &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.resolveSections(cfg); got != tt.want {
+				t.Errorf("resolveSections() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
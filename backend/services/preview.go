@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+// PreviewOptions control how an HTML preview is prepared for a browser.
+type PreviewOptions struct {
+	// AssetBaseURL is the public origin serving /static, so the preview
+	// works when embedded on another origin.
+	AssetBaseURL string
+	// Print applies the print stylesheet and frames the content at the
+	// printed page width, as Chrome does when producing the PDF.
+	Print bool
+}
+
+var (
+	printMediaPattern  = regexp.MustCompile(`@media\s+print\b`)
+	screenMediaPattern = regexp.MustCompile(`@media\s+screen\b`)
+	staticAssetPattern = regexp.MustCompile(`(?i)(\b(?:src|href)\s*=\s*["'])/static/`)
+)
+
+// PreviewItinerary renders the same HTML that is printed to PDF, with static
+// assets served over HTTP instead of file:// URLs. Proforma invoices are
+// previewed as drafts and are not issued a number.
+func (s *PDFService) PreviewItinerary(request *models.ItineraryRequest, opts PreviewOptions) (string, error) {
+	html, layout, err := s.renderHTML(request, "")
+	if err != nil {
+		return "", err
+	}
+
+	html = rebaseStaticAssets(html, opts.AssetBaseURL)
+
+	if opts.Print {
+		html = emulatePrintMedia(html, layout)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"htmlSize": len(html),
+		"print":    opts.Print,
+	}).Info("Itinerary preview rendered")
+
+	return html, nil
+}
+
+// rebaseStaticAssets points src and href attributes that start with /static/
+// at baseURL. Text that merely mentions /static/ is left alone.
+func rebaseStaticAssets(html, baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		return html
+	}
+	return staticAssetPattern.ReplaceAllString(html, "${1}"+strings.ReplaceAll(baseURL, "$", "$$")+"/static/")
+}
+
+// emulatePrintMedia turns print-only rules on and screen-only rules off, then
+// frames the body at the page's printable width.
+func emulatePrintMedia(html string, layout *pageLayout) string {
+	width, height := layout.pageSize()
+
+	html = printMediaPattern.ReplaceAllString(html, "@media all")
+	html = screenMediaPattern.ReplaceAllString(html, "@media not all")
+
+	frame := fmt.Sprintf(`<style>
+      html { background: #e5e7eb !important; }
+      body {
+        box-sizing: border-box !important;
+        width: %[1]gin !important;
+        min-height: %[2]gin !important;
+        margin: 24px auto !important;
+        padding: %[3]gin %[4]gin %[5]gin %[6]gin !important;
+        background: #ffffff !important;
+        box-shadow: 0 2px 12px rgba(0, 0, 0, 0.15);
+      }
+    </style>
+  </head>`,
+		width, height,
+		layout.MarginTop, layout.MarginRight, layout.MarginBottom, layout.MarginLeft)
+
+	return strings.Replace(html, "</head>", frame, 1)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRebaseStaticAssets(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		baseURL string
+		want    string
+	}{
+		{
+			name:    "src and href",
+			html:    `<img src="/static/logo.png"><link href='/static/app.css'>`,
+			baseURL: "https://pdf.vigovia.com/",
+			want:    `<img src="https://pdf.vigovia.com/static/logo.png"><link href='https://pdf.vigovia.com/static/app.css'>`,
+		},
+		{
+			name:    "text and other attributes untouched",
+			html:    `<p>Files under /static/ are public</p><a data-path="/static/x" href="/docs/static/y">`,
+			baseURL: "https://pdf.vigovia.com",
+			want:    `<p>Files under /static/ are public</p><a data-path="/static/x" href="/docs/static/y">`,
+		},
+		{
+			name:    "empty base keeps relative paths",
+			html:    `<img src="/static/logo.png">`,
+			baseURL: "",
+			want:    `<img src="/static/logo.png">`,
+		},
+		{
+			name:    "dollar in base is literal",
+			html:    `<img SRC = "/static/logo.png">`,
+			baseURL: "https://cdn.example/$1",
+			want:    `<img SRC = "https://cdn.example/$1/static/logo.png">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebaseStaticAssets(tt.html, tt.baseURL); got != tt.want {
+				t.Errorf("rebaseStaticAssets() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmulatePrintMedia(t *testing.T) {
+	html := `<html><head><style>@media print { .a {} } @media screen { .b {} }</style></head><body></body></html>`
+
+	tests := []struct {
+		name      string
+		landscape bool
+		wantWidth string
+		wantMin   string
+	}{
+		{"portrait", false, "width: 8.27in", "min-height: 11.69in"},
+		{"landscape", true, "width: 11.69in", "min-height: 8.27in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &pageLayout{Width: 8.27, Height: 11.69, Landscape: tt.landscape, MarginTop: 0.5, MarginBottom: 0.5, MarginLeft: 0.5, MarginRight: 0.5}
+			got := emulatePrintMedia(html, layout)
+
+			for _, want := range []string{tt.wantWidth, tt.wantMin, "@media all", "@media not all"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("emulatePrintMedia() is missing %q:\n%s", want, got)
+				}
+			}
+			if strings.Contains(got, "@media print") || strings.Contains(got, "@media screen") {
+				t.Errorf("emulatePrintMedia() left media queries in place:\n%s", got)
+			}
+		})
+	}
+}
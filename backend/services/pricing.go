@@ -0,0 +1,379 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+// tcsCurrency is the currency TCS is collected in. It is an Indian income
+// tax, so packages priced in other currencies are never charged it.
+const tcsCurrency = "INR"
+
+// Line item categories, in the order the breakdown lists them.
+const (
+	categoryFlights    = "Flights"
+	categoryHotels     = "Hotels"
+	categoryActivities = "Activities"
+	categoryTransfers  = "Transfers"
+	categoryOther      = "Other Charges"
+)
+
+var costCategories = []string{categoryFlights, categoryHotels, categoryActivities, categoryTransfers, categoryOther}
+
+// PricingEngine computes package prices from the line items of a request:
+// prices times travellers or groups, markup, discounts, GST and, for
+// overseas packages, TCS.
+type PricingEngine struct {
+	mode          string
+	markupPercent float64
+	gstPercent    float64
+	basis         map[string]string
+	tcs           config.TCSConfig
+	tcsThreshold  models.Money
+}
+
+func NewPricingEngine() *PricingEngine {
+	cfg := config.AppConfig.Pricing
+
+	engine := &PricingEngine{
+		mode:          models.PricingReconcile,
+		markupPercent: cfg.MarkupPercent,
+		gstPercent:    cfg.GSTPercent,
+		basis: map[string]string{
+			categoryFlights:    models.PricePerPax,
+			categoryHotels:     models.PricePerGroup,
+			categoryActivities: models.PricePerPax,
+			categoryTransfers:  models.PricePerGroup,
+		},
+		tcs: cfg.TCS,
+	}
+
+	switch mode := strings.ToLower(cfg.Mode); mode {
+	case "", models.PricingReconcile:
+	case models.PricingOverride:
+		engine.mode = mode
+	default:
+		logrus.WithField("mode", cfg.Mode).Warn("Unknown pricing mode, reconciling totals")
+	}
+
+	for category := range engine.basis {
+		switch basis := strings.ToLower(cfg.Basis[strings.ToLower(category)]); basis {
+		case "":
+		case models.PricePerPax, models.PricePerGroup:
+			engine.basis[category] = basis
+		default:
+			logrus.WithFields(logrus.Fields{
+				"category": category,
+				"basis":    basis,
+			}).Warn("Unknown price basis, keeping the default")
+		}
+	}
+
+	if cfg.TCS.Threshold != "" {
+		threshold, err := models.ParseMoney(cfg.TCS.Threshold)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid TCS threshold, charging the standard rate on the whole amount")
+		} else {
+			engine.tcsThreshold = threshold
+		}
+	}
+
+	return engine
+}
+
+// Apply prices a request that asks for it, one with a "pricing" object, and
+// brings its payment in line: missing totals are filled in, and supplied
+// ones are replaced in override mode or compared in reconcile mode, with
+// differences returned as pricing_total conflicts. The request should be a
+// copy from deriveFields, since hotel nights are taken from it.
+func (e *PricingEngine) Apply(request *models.ItineraryRequest) (*models.CostBreakdown, []derivedConflict, error) {
+	if request.Pricing == nil {
+		return nil, nil, nil
+	}
+
+	breakdown, err := e.Price(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	currency := requestCurrency(request)
+	payment := &request.Payment
+
+	var conflicts []derivedConflict
+	reconcile := func(field, name string, supplied *models.Money, computed models.Money) {
+		if breakdown.Mode == models.PricingReconcile && supplied.IsSet() {
+			if supplied.Valid() && supplied.Cmp(computed) != 0 {
+				conflicts = append(conflicts, derivedConflict{"pricing_total", ruleViolation{field, fmt.Sprintf(
+					"%s %s does not match the computed %s", name, currency.FormatMoney(*supplied), currency.FormatMoney(computed))}})
+			}
+			return
+		}
+		*supplied = computed
+	}
+	reconcile("payment.totalAmount", "totalAmount", &payment.TotalAmount, breakdown.Total)
+	if !breakdown.TCS.IsZero() || payment.TCS.IsSet() {
+		reconcile("payment.tcs", "tcs", &payment.TCS, breakdown.TCS)
+	}
+
+	return breakdown, conflicts, nil
+}
+
+// Price computes the cost breakdown of a request from its line items. The
+// supplied payment is only recorded, never used.
+func (e *PricingEngine) Price(request *models.ItineraryRequest) (*models.CostBreakdown, error) {
+	options := request.Pricing
+	if options == nil {
+		options = &models.PricingOptions{}
+	}
+
+	currency := requestCurrency(request)
+	travelers := request.Trip.Travelers
+	if travelers < 1 {
+		travelers = 1
+	}
+
+	// amount reads a request amount in the currency's minor units.
+	amount := func(field string, value models.Money) (models.Money, error) {
+		if !value.Valid() {
+			return models.Money{}, &fieldError{field, fmt.Errorf("%w %q", models.ErrInvalidAmount, value.String())}
+		}
+		rescaled, err := value.Rescale(currency.Decimals)
+		if err != nil {
+			return models.Money{}, &fieldError{field, fmt.Errorf("%s allows at most %d decimal places", currency.Code, currency.Decimals)}
+		}
+		return rescaled.WithCurrency(currency.Code), nil
+	}
+	zero, _ := models.NewMoney(0, currency.Code)
+
+	markupPercent := e.markupPercent
+	if options.MarkupPercent != nil {
+		markupPercent = *options.MarkupPercent
+	}
+	gstPercent := e.gstPercent
+	if options.GSTPercent != nil {
+		gstPercent = *options.GSTPercent
+	}
+	mode := e.mode
+	if options.Mode != "" {
+		mode = options.Mode
+	}
+
+	breakdown := &models.CostBreakdown{
+		Mode:          mode,
+		Currency:      currency.Code,
+		Travelers:     travelers,
+		MarkupPercent: markupPercent,
+		GSTPercent:    gstPercent,
+		SuppliedTotal: request.Payment.TotalAmount,
+		SuppliedTCS:   request.Payment.TCS,
+	}
+
+	var lineErr error
+	addLine := func(category, field, description, basis string, price models.Money, quantity int) {
+		if lineErr != nil || !price.IsSet() || price.IsZero() || quantity < 1 {
+			return
+		}
+		unit, err := amount(field, price)
+		if err != nil {
+			lineErr = err
+			return
+		}
+		cost := unit.Mul(int64(quantity))
+		unit = unit.Add(unit.Percent(markupPercent))
+		breakdown.Lines = append(breakdown.Lines, models.CostLine{
+			Category:    category,
+			Description: description,
+			Basis:       basis,
+			UnitPrice:   unit,
+			Quantity:    quantity,
+			Amount:      unit.Mul(int64(quantity)),
+		})
+		breakdown.Cost = breakdown.Cost.Add(cost)
+	}
+
+	perPax := func(basis string) int {
+		if basis == models.PricePerPax {
+			return travelers
+		}
+		return 1
+	}
+
+	basis := e.resolveBasis(options.Basis)
+
+	seenFlights := make(map[string]bool)
+	flight := func(field string, f models.Flight) {
+		if f.ID != "" && seenFlights[f.ID] {
+			return
+		}
+		seenFlights[f.ID] = true
+		description := strings.TrimSpace(fmt.Sprintf("%s %s", f.Airline, f.FlightNumber))
+		if f.Route != "" {
+			description += ", " + f.Route
+		}
+		addLine(categoryFlights, field, description, basis[categoryFlights], f.Price, perPax(basis[categoryFlights]))
+	}
+	for i, f := range request.Flights {
+		flight(fmt.Sprintf("flights[%d].price", i), f)
+	}
+	for i, day := range request.Itinerary.Days {
+		for j, f := range day.Flights {
+			flight(fmt.Sprintf("itinerary.days[%d].flights[%d].price", i, j), f)
+		}
+	}
+
+	for i, hotel := range request.Hotels {
+		description := fmt.Sprintf("%s, %s (%d %s)", hotel.HotelName, hotel.City, hotel.Nights, pluralize(hotel.Nights, "night"))
+		addLine(categoryHotels, fmt.Sprintf("hotels[%d].pricePerNight", i), description, basis[categoryHotels],
+			hotel.PricePerNight, hotel.Nights*perPax(basis[categoryHotels]))
+	}
+
+	for i, day := range request.Itinerary.Days {
+		for j, activity := range day.Activities {
+			addLine(categoryActivities, fmt.Sprintf("itinerary.days[%d].activities[%d].price", i, j), activity.Name,
+				basis[categoryActivities], activity.Price, perPax(basis[categoryActivities]))
+		}
+	}
+
+	for i, day := range request.Itinerary.Days {
+		for j, transfer := range day.Transfers {
+			// A group transfer is priced per vehicle.
+			quantity := perPax(basis[categoryTransfers])
+			if basis[categoryTransfers] == models.PricePerGroup && transfer.Capacity > 0 {
+				quantity = (travelers + transfer.Capacity - 1) / transfer.Capacity
+			}
+			description := fmt.Sprintf("%s: %s to %s", transfer.Type, transfer.From, transfer.To)
+			addLine(categoryTransfers, fmt.Sprintf("itinerary.days[%d].transfers[%d].price", i, j), description,
+				basis[categoryTransfers], transfer.Price, quantity)
+		}
+	}
+
+	for i, item := range options.Items {
+		itemBasis := item.Basis
+		if itemBasis == "" {
+			itemBasis = models.PricePerGroup
+		}
+		quantity := item.Quantity
+		if quantity < 1 {
+			quantity = 1
+		}
+		addLine(categoryOther, fmt.Sprintf("pricing.items[%d].price", i), item.Description, itemBasis,
+			item.Price, quantity*perPax(itemBasis))
+	}
+
+	if lineErr != nil {
+		return nil, lineErr
+	}
+
+	subtotal := zero
+	for _, name := range costCategories {
+		category := models.CostCategory{Name: name, Amount: zero}
+		for _, line := range breakdown.Lines {
+			if line.Category == name {
+				category.Items++
+				category.Amount = category.Amount.Add(line.Amount)
+			}
+		}
+		if category.Items > 0 {
+			breakdown.Categories = append(breakdown.Categories, category)
+			subtotal = subtotal.Add(category.Amount)
+		}
+	}
+	breakdown.Cost = zero.Add(breakdown.Cost)
+	breakdown.Markup = subtotal.Sub(breakdown.Cost)
+	breakdown.Subtotal = subtotal
+
+	discount := subtotal.Percent(options.DiscountPercent)
+	if options.Discount.IsSet() {
+		flat, err := amount("pricing.discount", options.Discount)
+		if err != nil {
+			return nil, err
+		}
+		discount = discount.Add(flat)
+	}
+	if discount.Cmp(subtotal) > 0 {
+		return nil, &fieldError{"pricing.discount", fmt.Errorf("discount of %s is more than the subtotal of %s",
+			currency.FormatMoney(discount), currency.FormatMoney(subtotal))}
+	}
+	breakdown.Discount = discount
+
+	breakdown.Taxable = subtotal.Sub(discount)
+	breakdown.GST = breakdown.Taxable.Percent(gstPercent)
+	breakdown.Total = breakdown.Taxable.Add(breakdown.GST)
+	breakdown.PerPax = breakdown.Total.Div(int64(travelers))
+
+	breakdown.TCS = zero
+	if options.Overseas && currency.Code == tcsCurrency {
+		breakdown.TCS = e.tcsOn(breakdown.Total)
+	}
+	breakdown.Payable = breakdown.Total.Add(breakdown.TCS)
+
+	return breakdown, nil
+}
+
+// resolveBasis overlays the request's price bases on the configured ones.
+func (e *PricingEngine) resolveBasis(requested models.PriceBasis) map[string]string {
+	basis := make(map[string]string, len(e.basis))
+	for category, value := range e.basis {
+		basis[category] = value
+	}
+	for category, value := range map[string]string{
+		categoryFlights:    requested.Flights,
+		categoryHotels:     requested.Hotels,
+		categoryActivities: requested.Activities,
+		categoryTransfers:  requested.Transfers,
+	} {
+		if value != "" {
+			basis[category] = value
+		}
+	}
+	return basis
+}
+
+// tcsOn charges the standard rate up to the threshold and the high rate on
+// the rest. The threshold applies per package here; amounts already spent
+// by the traveller in the financial year are not known.
+func (e *PricingEngine) tcsOn(total models.Money) models.Money {
+	threshold := e.tcsThreshold
+	if !threshold.IsSet() || threshold.IsZero() || total.Cmp(threshold) <= 0 {
+		return total.Percent(e.tcs.RatePercent)
+	}
+	threshold, err := threshold.Rescale(total.Scale())
+	if err != nil {
+		return total.Percent(e.tcs.RatePercent)
+	}
+	threshold = threshold.WithCurrency(total.Currency())
+	return threshold.Percent(e.tcs.RatePercent).Add(total.Sub(threshold).Percent(e.tcs.HighRatePercent))
+}
+
+// Quote prices a request without rendering it, validating it first. Requests
+// without a "pricing" object are priced with the configured defaults.
+func (s *PDFService) Quote(request *models.ItineraryRequest) (*models.CostBreakdown, []models.APIError, error) {
+	if request.Pricing == nil {
+		priced := *request
+		priced.Pricing = &models.PricingOptions{}
+		request = &priced
+	}
+
+	problems, warnings := s.Validate(request)
+	if len(problems) > 0 {
+		return nil, warnings, &ValidationError{Errors: problems, Warnings: warnings}
+	}
+
+	derived, _ := deriveFields(request)
+	breakdown, _, err := s.pricing.Apply(derived)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return breakdown, warnings, nil
+}
+
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
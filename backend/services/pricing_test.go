@@ -0,0 +1,267 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+// newTestPricingEngine builds an engine from cfg instead of config.yaml.
+func newTestPricingEngine(t *testing.T, cfg config.PricingConfig) *PricingEngine {
+	t.Helper()
+	original := config.AppConfig.Pricing
+	t.Cleanup(func() { config.AppConfig.Pricing = original })
+	config.AppConfig.Pricing = cfg
+	return NewPricingEngine()
+}
+
+var testPricingConfig = config.PricingConfig{
+	MarkupPercent: 10,
+	GSTPercent:    5,
+	TCS:           config.TCSConfig{RatePercent: 5, HighRatePercent: 20, Threshold: "1000000"},
+}
+
+func percent(p float64) *float64 { return &p }
+
+// pricedRequest is a two-traveller INR trip with one priced item of each
+// kind: cost 10000x2 + 5000x3 + 1000x2 + 3000 + 2500x2 = 45000.
+func pricedRequest(t *testing.T) *models.ItineraryRequest {
+	flight := models.Flight{ID: "f1", Airline: "Air India", FlightNumber: "AI 101", Price: mustParseMoney(t, "10000")}
+	return &models.ItineraryRequest{
+		Currency: "INR",
+		Trip:     models.Trip{Travelers: 2},
+		Flights:  []models.Flight{flight},
+		Hotels:   []models.Hotel{{HotelName: "Hotel", City: "Tokyo", Nights: 3, PricePerNight: mustParseMoney(t, "5000")}},
+		Itinerary: models.Itinerary{Days: []models.Day{{
+			Flights:    []models.Flight{flight},
+			Activities: []models.Activity{{Name: "Tour", Price: mustParseMoney(t, "1000")}},
+			Transfers:  []models.Transfer{{Type: "Car", From: "A", To: "B", Capacity: 4, Price: mustParseMoney(t, "3000")}},
+		}}},
+		Pricing: &models.PricingOptions{
+			Items: []models.PriceItem{{Description: "Visa", Price: mustParseMoney(t, "2500"), Basis: models.PricePerPax}},
+		},
+	}
+}
+
+func TestPricingEnginePrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(*models.ItineraryRequest)
+		wantCost     string
+		wantSubtotal string
+		wantTotal    string
+		wantPerPax   string
+		wantTCS      string
+		wantField    string
+	}{
+		{
+			name:         "defaults",
+			wantCost:     "45000.00",
+			wantSubtotal: "49500.00",
+			wantTotal:    "51975.00",
+			wantPerPax:   "25987.50",
+			wantTCS:      "0.00",
+		},
+		{
+			name: "request overrides markup, GST and basis",
+			modify: func(r *models.ItineraryRequest) {
+				r.Pricing.MarkupPercent = percent(0)
+				r.Pricing.GSTPercent = percent(0)
+				r.Pricing.Basis.Flights = models.PricePerGroup
+			},
+			wantCost:     "35000.00",
+			wantSubtotal: "35000.00",
+			wantTotal:    "35000.00",
+			wantPerPax:   "17500.00",
+			wantTCS:      "0.00",
+		},
+		{
+			name: "group transfers are priced per vehicle",
+			modify: func(r *models.ItineraryRequest) {
+				r.Trip.Travelers = 5
+				r.Pricing.MarkupPercent = percent(0)
+				r.Pricing.GSTPercent = percent(0)
+			},
+			// 10000x5 + 15000 + 1000x5 + 3000x2 + 2500x5
+			wantCost:     "88500.00",
+			wantSubtotal: "88500.00",
+			wantTotal:    "88500.00",
+			wantPerPax:   "17700.00",
+			wantTCS:      "0.00",
+		},
+		{
+			name: "percent and flat discounts",
+			modify: func(r *models.ItineraryRequest) {
+				r.Pricing.DiscountPercent = 10
+				r.Pricing.Discount = mustParseMoney(t, "50")
+			},
+			// 49500 - 4950 - 50 = 44500, plus 5% GST
+			wantCost:     "45000.00",
+			wantSubtotal: "49500.00",
+			wantTotal:    "46725.00",
+			wantPerPax:   "23362.50",
+			wantTCS:      "0.00",
+		},
+		{
+			name:         "overseas INR packages pay TCS",
+			modify:       func(r *models.ItineraryRequest) { r.Pricing.Overseas = true },
+			wantCost:     "45000.00",
+			wantSubtotal: "49500.00",
+			wantTotal:    "51975.00",
+			wantPerPax:   "25987.50",
+			wantTCS:      "2598.75",
+		},
+		{
+			name: "no TCS outside INR",
+			modify: func(r *models.ItineraryRequest) {
+				r.Currency = "USD"
+				r.Pricing.Overseas = true
+			},
+			wantCost:     "45000.00",
+			wantSubtotal: "49500.00",
+			wantTotal:    "51975.00",
+			wantPerPax:   "25987.50",
+			wantTCS:      "0.00",
+		},
+		{
+			name:      "discount above the subtotal",
+			modify:    func(r *models.ItineraryRequest) { r.Pricing.Discount = mustParseMoney(t, "50000") },
+			wantField: "pricing.discount",
+		},
+		{
+			name:      "too many decimals for the currency",
+			modify:    func(r *models.ItineraryRequest) { r.Hotels[0].PricePerNight = mustParseMoney(t, "10.005") },
+			wantField: "hotels[0].pricePerNight",
+		},
+		{
+			name: "unreadable price",
+			modify: func(r *models.ItineraryRequest) {
+				r.Itinerary.Days[0].Activities[0].Price.UnmarshalJSON([]byte(`"lots"`))
+			},
+			wantField: "itinerary.days[0].activities[0].price",
+		},
+	}
+
+	engine := newTestPricingEngine(t, testPricingConfig)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := pricedRequest(t)
+			if tt.modify != nil {
+				tt.modify(request)
+			}
+
+			breakdown, err := engine.Price(request)
+			if tt.wantField != "" {
+				var fe *fieldError
+				if !errors.As(err, &fe) || fe.field != tt.wantField {
+					t.Fatalf("Price() error = %v, want a fieldError on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Price() error = %v", err)
+			}
+
+			got := []string{breakdown.Cost.String(), breakdown.Subtotal.String(), breakdown.Total.String(), breakdown.PerPax.String(), breakdown.TCS.String()}
+			want := []string{tt.wantCost, tt.wantSubtotal, tt.wantTotal, tt.wantPerPax, tt.wantTCS}
+			if !equalStrings(got, want) {
+				t.Errorf("cost, subtotal, total, per pax, TCS = %v, want %v", got, want)
+			}
+			if sum := breakdown.Total.Add(breakdown.TCS); breakdown.Payable.Cmp(sum) != 0 {
+				t.Errorf("payable = %s, want total + TCS = %s", breakdown.Payable, sum)
+			}
+		})
+	}
+}
+
+func TestPricingEngineCountsSharedFlightsOnce(t *testing.T) {
+	engine := newTestPricingEngine(t, testPricingConfig)
+	breakdown, err := engine.Price(pricedRequest(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flights := 0
+	for _, line := range breakdown.Lines {
+		if line.Category == categoryFlights {
+			flights++
+		}
+	}
+	if flights != 1 {
+		t.Errorf("flight lines = %d, want 1 for a flight listed twice", flights)
+	}
+}
+
+func TestPricingEngineTCS(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold string
+		total     string
+		want      string
+	}{
+		{"below the threshold", "1000000", "500000.00", "25000.00"},
+		{"at the threshold", "1000000", "1000000.00", "50000.00"},
+		{"above the threshold", "1000000", "1200000.00", "90000.00"},
+		{"no threshold", "", "1200000.00", "60000.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPricingConfig
+			cfg.TCS.Threshold = tt.threshold
+			engine := newTestPricingEngine(t, cfg)
+
+			total := mustParseMoney(t, tt.total).WithCurrency("INR")
+			if got := engine.tcsOn(total).String(); got != tt.want {
+				t.Errorf("tcsOn(%s) = %s, want %s", tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPricingEngineApply(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		suppliedTotal string
+		wantTotal     string
+		wantConflict  bool
+	}{
+		{"missing total is filled", models.PricingReconcile, "", "51975.00", false},
+		{"matching total is kept", models.PricingReconcile, "51975", "51975", false},
+		{"reconcile reports a mismatch", models.PricingReconcile, "50000", "50000", true},
+		{"override replaces the total", models.PricingOverride, "50000", "51975.00", false},
+	}
+
+	engine := newTestPricingEngine(t, testPricingConfig)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := pricedRequest(t)
+			request.Pricing.Mode = tt.mode
+			if tt.suppliedTotal != "" {
+				request.Payment.TotalAmount = mustParseMoney(t, tt.suppliedTotal)
+			}
+
+			_, conflicts, err := engine.Apply(request)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := request.Payment.TotalAmount.String(); got != tt.wantTotal {
+				t.Errorf("totalAmount = %s, want %s", got, tt.wantTotal)
+			}
+			if gotConflict := len(conflicts) == 1 && conflicts[0].rule == "pricing_total"; gotConflict != tt.wantConflict || len(conflicts) > 1 {
+				t.Errorf("conflicts = %v, want a pricing_total conflict: %v", conflicts, tt.wantConflict)
+			}
+		})
+	}
+
+	unpriced := pricedRequest(t)
+	unpriced.Pricing = nil
+	if breakdown, conflicts, err := engine.Apply(unpriced); breakdown != nil || conflicts != nil || err != nil {
+		t.Errorf("Apply() without pricing = %v, %v, %v, want nothing", breakdown, conflicts, err)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrRenderQueueFull   = errors.New("render queue is full")
+	ErrRenderQueueClosed = errors.New("render queue is closed")
+)
+
+// RenderQueue bounds how many renders run at once and how many may wait.
+// Submissions beyond the capacity are rejected immediately so callers can
+// apply backpressure instead of piling up on Chrome.
+type RenderQueue struct {
+	jobs       chan *renderJob
+	workers    int
+	retryAfter time.Duration
+	wg         sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	stats  models.RenderQueueStats
+}
+
+type renderJob struct {
+	ctx   context.Context
+	fn    func(ctx context.Context) error
+	done  chan error
+	state atomic.Int32
+}
+
+// A job is either claimed by a worker or abandoned by its caller, never both.
+const (
+	jobQueued int32 = iota
+	jobStarted
+	jobAbandoned
+)
+
+func NewRenderQueue() *RenderQueue {
+	cfg := config.AppConfig.PDF.Queue
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.Capacity < 0 {
+		cfg.Capacity = 0
+	}
+
+	q := &RenderQueue{
+		jobs:       make(chan *renderJob, cfg.Capacity),
+		workers:    cfg.Workers,
+		retryAfter: cfg.RetryAfter,
+	}
+	q.stats.Workers = cfg.Workers
+	q.stats.Capacity = cfg.Capacity
+
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *RenderQueue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		// The caller may have given up while the job was waiting.
+		if !job.state.CompareAndSwap(jobQueued, jobStarted) {
+			continue
+		}
+		if err := job.ctx.Err(); err != nil {
+			job.done <- err
+			continue
+		}
+
+		q.mu.Lock()
+		q.stats.Active++
+		q.mu.Unlock()
+
+		err := job.fn(job.ctx)
+
+		q.mu.Lock()
+		q.stats.Active--
+		q.stats.Completed++
+		q.mu.Unlock()
+
+		job.done <- err
+	}
+}
+
+// Do runs fn on a queue worker and waits for it to finish. It returns
+// ErrRenderQueueFull without waiting when no slot is free. A job abandoned
+// through ctx while still queued never runs; once fn has started Do waits
+// for it to return, so no more than the configured number of renders ever
+// run and fn never outlives its caller.
+func (q *RenderQueue) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	job := &renderJob{
+		ctx:  ctx,
+		fn:   fn,
+		done: make(chan error, 1),
+	}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrRenderQueueClosed
+	}
+	select {
+	case q.jobs <- job:
+		q.mu.Unlock()
+	default:
+		q.stats.Rejected++
+		q.mu.Unlock()
+		logrus.WithField("pending", len(q.jobs)).Warn("Render queue full, rejecting request")
+		return ErrRenderQueueFull
+	}
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		if job.state.CompareAndSwap(jobQueued, jobAbandoned) {
+			return ctx.Err()
+		}
+		// fn sees the cancelled context and is expected to stop soon.
+		return <-job.done
+	}
+}
+
+// RetryAfter is the delay clients are asked to wait after a rejection.
+func (q *RenderQueue) RetryAfter() time.Duration {
+	return q.retryAfter
+}
+
+func (q *RenderQueue) Stats() models.RenderQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := q.stats
+	stats.Pending = len(q.jobs)
+	return stats
+}
+
+// Close stops accepting work and waits for queued renders to finish.
+func (q *RenderQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.jobs)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
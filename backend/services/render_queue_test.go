@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+)
+
+func newTestRenderQueue(t *testing.T, workers, capacity int) *RenderQueue {
+	t.Helper()
+
+	saved := config.AppConfig.PDF.Queue
+	config.AppConfig.PDF.Queue.Workers = workers
+	config.AppConfig.PDF.Queue.Capacity = capacity
+	q := NewRenderQueue()
+	config.AppConfig.PDF.Queue = saved
+
+	t.Cleanup(func() { q.Close(context.Background()) })
+	return q
+}
+
+func TestRenderQueueBoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		jobs    int
+	}{
+		{"single worker", 1, 20},
+		{"several workers", 3, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestRenderQueue(t, tt.workers, tt.jobs)
+
+			var active, peak atomic.Int32
+			var wg sync.WaitGroup
+			errs := make(chan error, tt.jobs)
+			for i := 0; i < tt.jobs; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- q.Do(context.Background(), func(ctx context.Context) error {
+						n := active.Add(1)
+						for {
+							p := peak.Load()
+							if n <= p || peak.CompareAndSwap(p, n) {
+								break
+							}
+						}
+						time.Sleep(time.Millisecond)
+						active.Add(-1)
+						return nil
+					})
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Errorf("Do() error = %v", err)
+				}
+			}
+			if got := peak.Load(); got > int32(tt.workers) {
+				t.Errorf("peak concurrency = %d, want at most %d", got, tt.workers)
+			}
+			if got := q.Stats().Completed; got != int64(tt.jobs) {
+				t.Errorf("Completed = %d, want %d", got, tt.jobs)
+			}
+		})
+	}
+}
+
+func TestRenderQueueRejectsWhenFull(t *testing.T) {
+	q := newTestRenderQueue(t, 1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go q.Do(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	// Fills the one queue slot behind the running job.
+	queued := make(chan error, 1)
+	go func() { queued <- q.Do(context.Background(), func(ctx context.Context) error { return nil }) }()
+	for q.Stats().Pending == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := q.Do(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrRenderQueueFull) {
+		t.Errorf("Do() error = %v, want %v", err, ErrRenderQueueFull)
+	}
+	close(release)
+	if err := <-queued; err != nil {
+		t.Errorf("queued Do() error = %v", err)
+	}
+}
+
+func TestRenderQueueCancel(t *testing.T) {
+	t.Run("waits for a started job", func(t *testing.T) {
+		q := newTestRenderQueue(t, 1, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		var finished atomic.Bool
+		result := make(chan error, 1)
+		go func() {
+			result <- q.Do(ctx, func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				finished.Store(true)
+				return ctx.Err()
+			})
+		}()
+
+		<-started
+		cancel()
+		if err := <-result; !errors.Is(err, context.Canceled) {
+			t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+		}
+		if !finished.Load() {
+			t.Error("Do() returned before the started job finished")
+		}
+	})
+
+	t.Run("abandons a queued job", func(t *testing.T) {
+		q := newTestRenderQueue(t, 1, 1)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		blocked := make(chan error, 1)
+		go func() {
+			blocked <- q.Do(context.Background(), func(ctx context.Context) error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var ran atomic.Bool
+		result := make(chan error, 1)
+		go func() {
+			result <- q.Do(ctx, func(ctx context.Context) error {
+				ran.Store(true)
+				return nil
+			})
+		}()
+		for q.Stats().Pending == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancel()
+		if err := <-result; !errors.Is(err, context.Canceled) {
+			t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+		}
+		close(release)
+		<-blocked
+		q.Close(context.Background())
+		if ran.Load() {
+			t.Error("abandoned job ran")
+		}
+	})
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// ruleViolation is one problem found by a business rule.
+type ruleViolation struct {
+	field   string
+	message string
+}
+
+// businessRule checks a relationship between fields that struct tags
+// cannot express. Errors block rendering; warnings are only reported. Rules
+// without a check report conflicts found while deriving fields.
+type businessRule struct {
+	name     string
+	severity string
+	check    func(*models.ItineraryRequest) []ruleViolation
+}
+
+// businessRules lists every rule with its default severity. Rules that real
+// itineraries commonly break on purpose, such as skipping day numbers or
+// leaving nights without a hotel, default to warnings.
+var businessRules = []businessRule{
+	{"trip_dates", SeverityError, checkTripDates},
+	{"trip_duration", SeverityWarning, nil},
+	{"day_dates_within_trip", SeverityError, checkDayDatesWithinTrip},
+	{"day_numbers_sequence", SeverityWarning, checkDayNumbersSequence},
+	{"day_dates_match_numbers", SeverityError, checkDayDatesMatchNumbers},
+	{"hotel_dates", SeverityError, checkHotelDates},
+	{"hotel_nights", SeverityWarning, nil},
+	{"hotel_overlap", SeverityError, checkHotelOverlap},
+	{"hotel_coverage", SeverityWarning, checkHotelCoverage},
+	{"flight_times", SeverityError, checkFlightTimes},
+	{"installments_total", SeverityError, checkInstallmentsTotal},
+	{"pricing_total", SeverityWarning, nil},
+}
+
+// RuleEngine runs the business rules enabled in validation.rules.
+type RuleEngine struct {
+	rules      []businessRule
+	severities map[string]string
+}
+
+func NewRuleEngine() *RuleEngine {
+	overrides := config.AppConfig.Validation.Rules
+
+	severities := make(map[string]string, len(businessRules))
+	var rules []businessRule
+	for _, rule := range businessRules {
+		if severity, ok := overrides[rule.name]; ok {
+			severity = strings.ToLower(severity)
+			switch severity {
+			case SeverityError, SeverityWarning, SeverityOff:
+				rule.severity = severity
+			default:
+				logrus.WithFields(logrus.Fields{
+					"rule":     rule.name,
+					"severity": severity,
+				}).Warn("Unknown rule severity, keeping the default")
+			}
+		}
+		severities[rule.name] = rule.severity
+		if rule.severity != SeverityOff && rule.check != nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	for name := range overrides {
+		if _, ok := severities[name]; !ok {
+			logrus.WithField("rule", name).Warn("Unknown business rule in validation.rules")
+		}
+	}
+
+	return &RuleEngine{rules: rules, severities: severities}
+}
+
+// Check runs every enabled rule and splits what it finds into errors and
+// warnings.
+func (e *RuleEngine) Check(request *models.ItineraryRequest) (problems, warnings []models.APIError) {
+	for _, rule := range e.rules {
+		for _, violation := range rule.check(request) {
+			e.report(rule.name, violation, &problems, &warnings)
+		}
+	}
+	return problems, warnings
+}
+
+// Conflicts reports values replaced while deriving fields, at the severity
+// of the rule each conflict belongs to.
+func (e *RuleEngine) Conflicts(conflicts []derivedConflict) (problems, warnings []models.APIError) {
+	for _, conflict := range conflicts {
+		e.report(conflict.rule, conflict.violation, &problems, &warnings)
+	}
+	return problems, warnings
+}
+
+func (e *RuleEngine) report(rule string, violation ruleViolation, problems, warnings *[]models.APIError) {
+	finding := models.APIError{
+		Field:   violation.field,
+		Message: violation.message,
+		Code:    strings.ToUpper(rule),
+	}
+	switch e.severities[rule] {
+	case SeverityError:
+		*problems = append(*problems, finding)
+	case SeverityWarning:
+		*warnings = append(*warnings, finding)
+	}
+}
+
+// tripRange returns the trip's first and last day when both parse and are
+// in order.
+func tripRange(request *models.ItineraryRequest) (time.Time, time.Time, bool) {
+	start, err := utils.ParseDate(request.Trip.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := utils.ParseDate(request.Trip.EndDate)
+	if err != nil || end.Before(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+func formatRuleDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func nightsBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func checkTripDates(request *models.ItineraryRequest) []ruleViolation {
+	var violations []ruleViolation
+
+	start, startErr := utils.ParseDate(request.Trip.StartDate)
+	if startErr != nil && request.Trip.StartDate != "" {
+		violations = append(violations, ruleViolation{"trip.startDate", "startDate must be a date such as 2024-12-15"})
+	}
+	end, endErr := utils.ParseDate(request.Trip.EndDate)
+	if endErr != nil && request.Trip.EndDate != "" {
+		violations = append(violations, ruleViolation{"trip.endDate", "endDate must be a date such as 2024-12-22"})
+	}
+
+	if startErr == nil && endErr == nil && end.Before(start) {
+		violations = append(violations, ruleViolation{"trip.endDate", "endDate is before startDate"})
+	}
+	return violations
+}
+
+func checkDayDatesWithinTrip(request *models.ItineraryRequest) []ruleViolation {
+	start, end, ok := tripRange(request)
+	if !ok {
+		return nil
+	}
+
+	var violations []ruleViolation
+	for i, day := range request.Itinerary.Days {
+		field := fmt.Sprintf("itinerary.days[%d].date", i)
+		date, err := utils.ParseDate(day.Date)
+		if err != nil {
+			if day.Date != "" {
+				violations = append(violations, ruleViolation{field, "date must be a date such as 2024-12-15"})
+			}
+			continue
+		}
+		if date.Before(start) || date.After(end) {
+			violations = append(violations, ruleViolation{field, fmt.Sprintf(
+				"%s is outside the trip (%s to %s)", formatRuleDate(date), formatRuleDate(start), formatRuleDate(end))})
+		}
+	}
+	return violations
+}
+
+func checkDayNumbersSequence(request *models.ItineraryRequest) []ruleViolation {
+	var violations []ruleViolation
+	seen := make(map[int]bool)
+	for i, day := range request.Itinerary.Days {
+		field := fmt.Sprintf("itinerary.days[%d].dayNumber", i)
+		switch {
+		case seen[day.DayNumber]:
+			violations = append(violations, ruleViolation{field, fmt.Sprintf("day %d appears more than once", day.DayNumber)})
+		case i == 0 && day.DayNumber != 1:
+			violations = append(violations, ruleViolation{field, fmt.Sprintf("itinerary starts at day %d instead of day 1", day.DayNumber)})
+		case i > 0 && day.DayNumber != request.Itinerary.Days[i-1].DayNumber+1:
+			violations = append(violations, ruleViolation{field, fmt.Sprintf(
+				"day %d follows day %d; expected day %d", day.DayNumber, request.Itinerary.Days[i-1].DayNumber, request.Itinerary.Days[i-1].DayNumber+1)})
+		}
+		seen[day.DayNumber] = true
+	}
+	return violations
+}
+
+func checkDayDatesMatchNumbers(request *models.ItineraryRequest) []ruleViolation {
+	start, _, ok := tripRange(request)
+	if !ok {
+		return nil
+	}
+
+	var violations []ruleViolation
+	for i, day := range request.Itinerary.Days {
+		date, err := utils.ParseDate(day.Date)
+		if err != nil || day.DayNumber < 1 {
+			continue
+		}
+		expected := start.AddDate(0, 0, day.DayNumber-1)
+		if !date.Equal(expected) {
+			violations = append(violations, ruleViolation{fmt.Sprintf("itinerary.days[%d].date", i), fmt.Sprintf(
+				"day %d should fall on %s but is dated %s", day.DayNumber, formatRuleDate(expected), formatRuleDate(date))})
+		}
+	}
+	return violations
+}
+
+// hotelStay is a hotel booking with parsed dates.
+type hotelStay struct {
+	index    int
+	name     string
+	checkIn  time.Time
+	checkOut time.Time
+}
+
+// hotelStays returns the stays whose dates parse and are in order.
+func hotelStays(request *models.ItineraryRequest) []hotelStay {
+	var stays []hotelStay
+	for i, hotel := range request.Hotels {
+		checkIn, err := utils.ParseDate(hotel.CheckIn)
+		if err != nil {
+			continue
+		}
+		checkOut, err := utils.ParseDate(hotel.CheckOut)
+		if err != nil || !checkOut.After(checkIn) {
+			continue
+		}
+		stays = append(stays, hotelStay{index: i, name: hotel.HotelName, checkIn: checkIn, checkOut: checkOut})
+	}
+	return stays
+}
+
+func checkHotelDates(request *models.ItineraryRequest) []ruleViolation {
+	var violations []ruleViolation
+	for i, hotel := range request.Hotels {
+		checkIn, inErr := utils.ParseDate(hotel.CheckIn)
+		if inErr != nil && hotel.CheckIn != "" {
+			violations = append(violations, ruleViolation{fmt.Sprintf("hotels[%d].checkIn", i), "checkIn must be a date such as 2024-12-15"})
+		}
+		checkOut, outErr := utils.ParseDate(hotel.CheckOut)
+		if outErr != nil && hotel.CheckOut != "" {
+			violations = append(violations, ruleViolation{fmt.Sprintf("hotels[%d].checkOut", i), "checkOut must be a date such as 2024-12-17"})
+		}
+		if inErr != nil || outErr != nil {
+			continue
+		}
+
+		if !checkOut.After(checkIn) {
+			violations = append(violations, ruleViolation{fmt.Sprintf("hotels[%d].checkOut", i), "checkOut must be after checkIn"})
+		}
+	}
+	return violations
+}
+
+func checkHotelOverlap(request *models.ItineraryRequest) []ruleViolation {
+	stays := hotelStays(request)
+	sort.SliceStable(stays, func(i, j int) bool {
+		return stays[i].checkIn.Before(stays[j].checkIn)
+	})
+
+	var violations []ruleViolation
+	for i := 1; i < len(stays); i++ {
+		// Compare with the stay that runs latest so far, so a long booking
+		// overlapping several later ones is caught for each of them.
+		latest := stays[0]
+		for _, stay := range stays[1:i] {
+			if stay.checkOut.After(latest.checkOut) {
+				latest = stay
+			}
+		}
+		if stays[i].checkIn.Before(latest.checkOut) {
+			violations = append(violations, ruleViolation{fmt.Sprintf("hotels[%d].checkIn", stays[i].index), fmt.Sprintf(
+				"stay at %s starts on %s, before the stay at %s checks out on %s",
+				stays[i].name, formatRuleDate(stays[i].checkIn), latest.name, formatRuleDate(latest.checkOut))})
+		}
+	}
+	return violations
+}
+
+func checkHotelCoverage(request *models.ItineraryRequest) []ruleViolation {
+	start, end, ok := tripRange(request)
+	if !ok || len(request.Hotels) == 0 {
+		return nil
+	}
+	stays := hotelStays(request)
+
+	var violations []ruleViolation
+	for _, stay := range stays {
+		if stay.checkIn.Before(start) || stay.checkOut.After(end) {
+			violations = append(violations, ruleViolation{fmt.Sprintf("hotels[%d]", stay.index), fmt.Sprintf(
+				"stay at %s (%s to %s) extends outside the trip", stay.name, formatRuleDate(stay.checkIn), formatRuleDate(stay.checkOut))})
+		}
+	}
+
+	covered := func(night time.Time) bool {
+		for _, stay := range stays {
+			if !night.Before(stay.checkIn) && night.Before(stay.checkOut) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Report each run of uncovered nights once.
+	var gapStart time.Time
+	inGap := false
+	for night := start; night.Before(end); night = night.AddDate(0, 0, 1) {
+		if !covered(night) {
+			if !inGap {
+				gapStart, inGap = night, true
+			}
+			continue
+		}
+		if inGap {
+			violations = append(violations, hotelGap(gapStart, night))
+			inGap = false
+		}
+	}
+	if inGap {
+		violations = append(violations, hotelGap(gapStart, end))
+	}
+	return violations
+}
+
+func hotelGap(from, to time.Time) ruleViolation {
+	nights := nightsBetween(from, to)
+	if nights == 1 {
+		return ruleViolation{"hotels", fmt.Sprintf("no hotel covers the night of %s", formatRuleDate(from))}
+	}
+	return ruleViolation{"hotels", fmt.Sprintf(
+		"no hotel covers the %d nights from %s to %s", nights, formatRuleDate(from), formatRuleDate(to.AddDate(0, 0, -1)))}
+}
+
+func checkFlightTimes(request *models.ItineraryRequest) []ruleViolation {
+	var violations []ruleViolation
+	for i, flight := range request.Flights {
+		violations = append(violations, checkFlightTime(fmt.Sprintf("flights[%d]", i), flight)...)
+	}
+	for d, day := range request.Itinerary.Days {
+		for i, flight := range day.Flights {
+			violations = append(violations, checkFlightTime(fmt.Sprintf("itinerary.days[%d].flights[%d]", d, i), flight)...)
+		}
+	}
+	return violations
+}
+
+// checkFlightTime compares departure and arrival. Bare times such as 23:30
+// carry no day, so an arrival earlier than departure is read as landing the
+// next day; only arrivals with a date or a "+N" day offset can be wrong.
+func checkFlightTime(field string, flight models.Flight) []ruleViolation {
+	departure, depExplicit, ok := parseFlightTime(flight.Date, flight.Departure)
+	if !ok {
+		return nil
+	}
+	arrival, arrExplicit, ok := parseFlightTime(flight.Date, flight.Arrival)
+	if !ok || (!depExplicit && !arrExplicit) {
+		return nil
+	}
+
+	if !arrival.After(departure) {
+		return []ruleViolation{{field + ".arrival", fmt.Sprintf(
+			"arrival %s is not after departure %s", flight.Arrival, flight.Departure)}}
+	}
+	return nil
+}
+
+var flightTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// parseFlightTime reads a full timestamp, or a time of day on the flight's
+// date with an optional "+N" day offset. explicit reports whether the value
+// pins down its day.
+func parseFlightTime(date, value string) (t time.Time, explicit bool, ok bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range flightTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true, true
+		}
+	}
+
+	clock, offset, hasOffset := strings.Cut(value, "+")
+	days := 0
+	if hasOffset {
+		var err error
+		days, err = strconv.Atoi(strings.TrimSpace(offset))
+		if err != nil {
+			return time.Time{}, false, false
+		}
+	}
+
+	parsed, err := utils.ParseClock(clock)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	day, err := utils.ParseDate(date)
+	if err != nil {
+		day = time.Time{}
+	}
+	t = day.AddDate(0, 0, days).Add(time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute)
+	return t, hasOffset, true
+}
+
+func checkInstallmentsTotal(request *models.ItineraryRequest) []ruleViolation {
+	total := request.Payment.TotalAmount
+	if len(request.Payment.Installments) == 0 || !total.IsSet() || !total.Valid() {
+		return nil
+	}
+
+	var sum models.Money
+	for _, installment := range request.Payment.Installments {
+		if !installment.Amount.Valid() {
+			return nil
+		}
+		sum = sum.Add(installment.Amount)
+	}
+
+	if sum.Cmp(total) != 0 {
+		currency := requestCurrency(request)
+		return []ruleViolation{{"payment.installments", fmt.Sprintf(
+			"installments add up to %s but totalAmount is %s", currency.FormatMoney(sum), currency.FormatMoney(total))}}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func ruleRequest(start, end string, days ...models.Day) *models.ItineraryRequest {
+	return &models.ItineraryRequest{
+		Trip:      models.Trip{StartDate: start, EndDate: end},
+		Itinerary: models.Itinerary{Days: days},
+	}
+}
+
+func ruleFields(violations []ruleViolation) []string {
+	var fields []string
+	for _, violation := range violations {
+		fields = append(fields, violation.field)
+	}
+	return fields
+}
+
+func mustParseMoney(t *testing.T, s string) models.Money {
+	t.Helper()
+	m, err := models.ParseMoney(s)
+	if err != nil {
+		t.Fatalf("ParseMoney(%q) error = %v", s, err)
+	}
+	return m
+}
+
+func TestBusinessRuleChecks(t *testing.T) {
+	hotel := func(name, in, out string) models.Hotel {
+		return models.Hotel{HotelName: name, CheckIn: in, CheckOut: out}
+	}
+	withHotels := func(request *models.ItineraryRequest, hotels ...models.Hotel) *models.ItineraryRequest {
+		request.Hotels = hotels
+		return request
+	}
+	withFlights := func(request *models.ItineraryRequest, flights ...models.Flight) *models.ItineraryRequest {
+		request.Flights = flights
+		return request
+	}
+	flight := func(date, departure, arrival string) models.Flight {
+		return models.Flight{Date: date, Departure: departure, Arrival: arrival}
+	}
+
+	tests := []struct {
+		name    string
+		check   func(*models.ItineraryRequest) []ruleViolation
+		request *models.ItineraryRequest
+		want    []string
+	}{
+		{"trip dates in order", checkTripDates, ruleRequest("2025-03-01", "2025-03-05"), nil},
+		{"trip ends before it starts", checkTripDates, ruleRequest("2025-03-05", "2025-03-01"), []string{"trip.endDate"}},
+		{"trip dates unparseable", checkTripDates, ruleRequest("soon", "later"), []string{"trip.startDate", "trip.endDate"}},
+
+		{"day inside the trip", checkDayDatesWithinTrip, ruleRequest("2025-03-01", "2025-03-05", models.Day{Date: "2025-03-03"}), nil},
+		{"day outside the trip", checkDayDatesWithinTrip,
+			ruleRequest("2025-03-01", "2025-03-05", models.Day{Date: "2025-03-01"}, models.Day{Date: "2025-03-06"}),
+			[]string{"itinerary.days[1].date"}},
+		{"undated days are skipped", checkDayDatesWithinTrip, ruleRequest("2025-03-01", "2025-03-05", models.Day{}), nil},
+
+		{"day numbers in sequence", checkDayNumbersSequence,
+			ruleRequest("", "", models.Day{DayNumber: 1}, models.Day{DayNumber: 2}), nil},
+		{"day numbers skip and repeat", checkDayNumbersSequence,
+			ruleRequest("", "", models.Day{DayNumber: 2}, models.Day{DayNumber: 4}, models.Day{DayNumber: 4}),
+			[]string{"itinerary.days[0].dayNumber", "itinerary.days[1].dayNumber", "itinerary.days[2].dayNumber"}},
+
+		{"day dates match numbers", checkDayDatesMatchNumbers,
+			ruleRequest("2025-03-01", "2025-03-05", models.Day{DayNumber: 1, Date: "2025-03-01"}, models.Day{DayNumber: 3, Date: "2025-03-03"}), nil},
+		{"day dated off its number", checkDayDatesMatchNumbers,
+			ruleRequest("2025-03-01", "2025-03-05", models.Day{DayNumber: 2, Date: "2025-03-04"}),
+			[]string{"itinerary.days[0].date"}},
+
+		{"hotel dates in order", checkHotelDates, withHotels(ruleRequest("", ""), hotel("A", "2025-03-01", "2025-03-03")), nil},
+		{"hotel checks out on arrival", checkHotelDates,
+			withHotels(ruleRequest("", ""), hotel("A", "2025-03-03", "2025-03-03"), hotel("B", "bad", "2025-03-03")),
+			[]string{"hotels[0].checkOut", "hotels[1].checkIn"}},
+
+		{"back-to-back hotels", checkHotelOverlap,
+			withHotels(ruleRequest("", ""), hotel("A", "2025-03-01", "2025-03-03"), hotel("B", "2025-03-03", "2025-03-05")), nil},
+		{"long stay overlaps later ones", checkHotelOverlap,
+			withHotels(ruleRequest("", ""),
+				hotel("C", "2025-03-04", "2025-03-05"),
+				hotel("A", "2025-03-01", "2025-03-06"),
+				hotel("B", "2025-03-02", "2025-03-03")),
+			[]string{"hotels[2].checkIn", "hotels[0].checkIn"}},
+
+		{"hotels cover every night", checkHotelCoverage,
+			withHotels(ruleRequest("2025-03-01", "2025-03-05"), hotel("A", "2025-03-01", "2025-03-03"), hotel("B", "2025-03-03", "2025-03-05")), nil},
+		{"gap and stay outside the trip", checkHotelCoverage,
+			withHotels(ruleRequest("2025-03-01", "2025-03-05"), hotel("A", "2025-02-28", "2025-03-02")),
+			[]string{"hotels[0]", "hotels"}},
+		{"no hotels is not a gap", checkHotelCoverage, ruleRequest("2025-03-01", "2025-03-05"), nil},
+
+		{"overnight flight with bare times", checkFlightTimes, withFlights(ruleRequest("", ""), flight("2025-03-01", "23:30", "06:10")), nil},
+		{"next-day arrival with offset", checkFlightTimes, withFlights(ruleRequest("", ""), flight("2025-03-01", "23:30", "06:10+1")), nil},
+		{"arrival before departure with timestamps", checkFlightTimes,
+			withFlights(ruleRequest("", ""), flight("2025-03-01", "2025-03-01T10:00", "2025-03-01T08:00")),
+			[]string{"flights[0].arrival"}},
+		{"day flight arrives before it departs", checkFlightTimes,
+			ruleRequest("", "", models.Day{Flights: []models.Flight{flight("2025-03-01", "10:00", "09:00+0")}}),
+			[]string{"itinerary.days[0].flights[0].arrival"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ruleFields(tt.check(tt.request))
+			if !equalStrings(got, tt.want) {
+				t.Errorf("violations on %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInstallmentsTotal(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        string
+		installments []string
+		wantMessage  string
+	}{
+		{"installments match", "100000", []string{"30000", "70000.00"}, ""},
+		{"installments short", "100000", []string{"30000", "60000"}, "installments add up to"},
+		{"no installments", "100000", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &models.ItineraryRequest{Payment: models.Payment{TotalAmount: mustParseMoney(t, tt.total)}}
+			for _, amount := range tt.installments {
+				request.Payment.Installments = append(request.Payment.Installments, models.Installment{Amount: mustParseMoney(t, amount)})
+			}
+
+			violations := checkInstallmentsTotal(request)
+			if tt.wantMessage == "" {
+				if len(violations) != 0 {
+					t.Errorf("checkInstallmentsTotal() = %v, want none", violations)
+				}
+				return
+			}
+			if len(violations) != 1 || !strings.Contains(violations[0].message, tt.wantMessage) {
+				t.Errorf("checkInstallmentsTotal() = %v, want a message containing %q", violations, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRuleEngineSeverities(t *testing.T) {
+	original := config.AppConfig.Validation.Rules
+	t.Cleanup(func() { config.AppConfig.Validation.Rules = original })
+
+	// The trip ends before it starts and day 2 follows day 2.
+	request := ruleRequest("2025-03-05", "2025-03-01", models.Day{DayNumber: 1}, models.Day{DayNumber: 1})
+
+	tests := []struct {
+		name         string
+		rules        map[string]string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{"defaults", nil, []string{"TRIP_DATES"}, []string{"DAY_NUMBERS_SEQUENCE"}},
+		{"promote a warning", map[string]string{"day_numbers_sequence": "ERROR"}, []string{"TRIP_DATES", "DAY_NUMBERS_SEQUENCE"}, nil},
+		{"demote and disable", map[string]string{"trip_dates": "warning", "day_numbers_sequence": "off"}, nil, []string{"TRIP_DATES"}},
+		{"unknown severity keeps the default", map[string]string{"trip_dates": "fatal"}, []string{"TRIP_DATES"}, []string{"DAY_NUMBERS_SEQUENCE"}},
+	}
+
+	codes := func(findings []models.APIError) []string {
+		var codes []string
+		for _, finding := range findings {
+			codes = append(codes, finding.Code)
+		}
+		return codes
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig.Validation.Rules = tt.rules
+			problems, warnings := NewRuleEngine().Check(request)
+
+			if got := codes(problems); !equalStrings(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+			}
+			if got := codes(warnings); !equalStrings(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestRuleEngineConflicts(t *testing.T) {
+	original := config.AppConfig.Validation.Rules
+	t.Cleanup(func() { config.AppConfig.Validation.Rules = original })
+	config.AppConfig.Validation.Rules = map[string]string{"hotel_nights": "error", "pricing_total": "off"}
+
+	conflicts := []derivedConflict{
+		{rule: "trip_duration", violation: ruleViolation{"trip.duration", "duration"}},
+		{rule: "hotel_nights", violation: ruleViolation{"hotels[0].nights", "nights"}},
+		{rule: "pricing_total", violation: ruleViolation{"payment.totalAmount", "total"}},
+	}
+	problems, warnings := NewRuleEngine().Conflicts(conflicts)
+
+	if len(problems) != 1 || problems[0].Field != "hotels[0].nights" || problems[0].Code != "HOTEL_NIGHTS" {
+		t.Errorf("errors = %+v, want the hotel_nights conflict", problems)
+	}
+	if len(warnings) != 1 || warnings[0].Field != "trip.duration" {
+		t.Errorf("warnings = %+v, want the trip_duration conflict", warnings)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+)
+
+const (
+	StorageBackendLocal  = "local"
+	StorageBackendS3     = "s3"
+	StorageBackendMemory = "memory"
+)
+
+var (
+	ErrObjectNotFound      = errors.New("object not found")
+	ErrPresignNotSupported = errors.New("storage backend does not support presigned URLs")
+	ErrPreconditionFailed  = errors.New("object changed or already exists")
+)
+
+// Storage is a flat key/value object store for generated documents. Keys use
+// forward slashes regardless of backend.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// PutIfAbsent stores an object only if the key is free, returning
+	// ErrPreconditionFailed when another writer created it first.
+	PutIfAbsent(ctx context.Context, key string, data []byte, contentType string) error
+	// PutIfMatch replaces an object only while its ETag is still etag,
+	// returning ErrPreconditionFailed when it changed since it was read.
+	PutIfMatch(ctx context.Context, key string, data []byte, contentType, etag string) error
+	// Get opens an object for reading. Callers must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List returns every object whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// PresignURL returns a URL that downloads the object directly from the
+	// backend until expiry.
+	PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+	// ETag changes whenever the object is written.
+	ETag string
+}
+
+// NewStorage builds the backend selected by pdf.storage_backend.
+func NewStorage() (Storage, error) {
+	cfg := config.AppConfig.PDF
+
+	switch strings.ToLower(cfg.StorageBackend) {
+	case "", StorageBackendLocal:
+		return NewLocalStorage(cfg.StoragePath), nil
+	case StorageBackendS3:
+		return NewS3Storage(cfg.S3)
+	case StorageBackendMemory:
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// readObject reads a whole object into memory.
+func readObject(ctx context.Context, storage Storage, key string) ([]byte, error) {
+	reader, _, err := storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+// LocalStorage stores objects as files under a root directory. It only works
+// for a single instance; replicas need a shared backend such as S3.
+type LocalStorage struct {
+	root string
+
+	// mu serialises replacing files so PutIfMatch can check and write
+	// atomically within the instance.
+	mu sync.Mutex
+}
+
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replace(key, path, data)
+}
+
+// PutIfAbsent creates the file with O_EXCL, so only one writer can win even
+// across processes.
+func (s *LocalStorage) PutIfAbsent(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := utils.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return ErrPreconditionFailed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", key, err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) PutIfMatch(ctx context.Context, key string, data []byte, contentType, etag string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if info.ETag != etag {
+		return ErrPreconditionFailed
+	}
+	return s.replace(key, path, data)
+}
+
+// replace atomically swaps in a new file for key; callers hold mu.
+func (s *LocalStorage) replace(key, path string, data []byte) error {
+	if err := utils.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	// The ETag is derived from the modification time, so it must move
+	// forward even when two writes land within the clock's resolution.
+	if previous, err := os.Stat(path); err == nil {
+		if written, err := os.Stat(tmp.Name()); err == nil && !written.ModTime().After(previous.ModTime()) {
+			modTime := previous.ModTime().Add(time.Microsecond)
+			if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+				return fmt.Errorf("failed to write %s: %w", key, err)
+			}
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	return file, s.objectInfo(key, stat), nil
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	return s.objectInfo(key, stat), nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var infos []ObjectInfo
+	err := filepath.WalkDir(s.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		infos = append(infos, *s.objectInfo(key, stat))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage directory: %w", err)
+	}
+	return infos, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+// path maps a key to a file under root, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+func (s *LocalStorage) objectInfo(key string, stat os.FileInfo) *ObjectInfo {
+	contentType := ""
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".pdf":
+		contentType = "application/pdf"
+	case ".json":
+		contentType = "application/json"
+	}
+	return &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+		ContentType: contentType,
+		ETag:        strconv.FormatInt(stat.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(stat.Size(), 36),
+	}
+}
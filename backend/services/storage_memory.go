@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStorage keeps objects in process memory. It is meant for local
+// development and as a stand-in for S3 when testing; nothing survives a
+// restart and objects are not shared between instances.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	writes  uint64
+}
+
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		objects: make(map[string]memoryObject),
+	}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(key, data, contentType)
+	return nil
+}
+
+func (s *MemoryStorage) PutIfAbsent(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.objects[key]; ok {
+		return ErrPreconditionFailed
+	}
+	s.put(key, data, contentType)
+	return nil
+}
+
+func (s *MemoryStorage) PutIfMatch(ctx context.Context, key string, data []byte, contentType, etag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[key]
+	if !ok {
+		return ErrObjectNotFound
+	}
+	if object.info.ETag != etag {
+		return ErrPreconditionFailed
+	}
+	s.put(key, data, contentType)
+	return nil
+}
+
+// put stores an object under a fresh ETag; callers hold mu.
+func (s *MemoryStorage) put(key string, data []byte, contentType string) {
+	s.writes++
+	s.objects[key] = memoryObject{
+		data: append([]byte(nil), data...),
+		info: ObjectInfo{
+			Key:         key,
+			Size:        int64(len(data)),
+			ModTime:     time.Now(),
+			ContentType: contentType,
+			ETag:        strconv.FormatUint(s.writes, 10),
+		},
+	}
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, nil, ErrObjectNotFound
+	}
+	info := object.info
+	return io.NopCloser(bytes.NewReader(object.data)), &info, nil
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	info := object.info
+	return &info, nil
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var infos []ObjectInfo
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, object.info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+	return infos, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStorage) PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
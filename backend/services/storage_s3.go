@@ -0,0 +1,438 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateFormat      = "20060102T150405Z"
+)
+
+// S3Storage talks to any S3-compatible service (AWS S3, MinIO, R2) over its
+// REST API, signing requests with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint     *url.URL
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	prefix       string
+	usePathStyle bool
+	client       *http.Client
+	now          func() time.Time
+}
+
+func NewS3Storage(cfg config.S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("pdf.s3.bucket is required for the s3 storage backend")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid pdf.s3.endpoint %q", cfg.Endpoint)
+	}
+
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &S3Storage{
+		endpoint:     endpoint,
+		region:       cfg.Region,
+		bucket:       cfg.Bucket,
+		accessKey:    cfg.AccessKeyID,
+		secretKey:    cfg.SecretAccessKey,
+		sessionToken: cfg.SessionToken,
+		prefix:       prefix,
+		usePathStyle: cfg.UsePathStyle,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		now:          time.Now,
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.put(ctx, key, data, contentType, nil)
+}
+
+// PutIfAbsent sends If-None-Match: *, which S3 evaluates atomically.
+func (s *S3Storage) PutIfAbsent(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.put(ctx, key, data, contentType, http.Header{"If-None-Match": {"*"}})
+}
+
+func (s *S3Storage) PutIfMatch(ctx context.Context, key string, data []byte, contentType, etag string) error {
+	return s.put(ctx, key, data, contentType, http.Header{"If-Match": {etag}})
+}
+
+func (s *S3Storage) put(ctx context.Context, key string, data []byte, contentType string, conditions http.Header) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, values := range conditions {
+		req.Header[name] = values
+	}
+
+	resp, err := s.do(req, sha256Hex(data))
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s.objectInfo(key, resp), nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s.objectInfo(key, resp), nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var infos []ObjectInfo
+	token := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.prefix+prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, sha256Hex(nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			infos = append(infos, ObjectInfo{
+				Key:     strings.TrimPrefix(object.Key, s.prefix),
+				Size:    object.Size,
+				ModTime: object.LastModified,
+				ETag:    object.ETag,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return infos, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, sha256Hex(nil))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > 7*24*time.Hour {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and 7 days")
+	}
+
+	target := s.objectURL(key, nil)
+	now := s.now().UTC()
+	amzDate := now.Format(s3DateFormat)
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		s3EscapePath(target.EscapedPath()),
+		s3CanonicalQuery(query),
+		"host:" + target.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonical))
+	target.RawQuery = s3CanonicalQuery(query)
+	return target.String(), nil
+}
+
+// newRequest builds a request for an object key, or for the bucket itself
+// when key is empty.
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := s.objectURL(key, query)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage request: %w", err)
+	}
+	return req, nil
+}
+
+// do signs and sends a request, turning S3 error responses into errors.
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, ErrPreconditionFailed
+	}
+
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+		if s3Err.Code == "ConditionalRequestConflict" {
+			// A concurrent conditional write to the same key won.
+			return nil, ErrPreconditionFailed
+		}
+		return nil, fmt.Errorf("s3 %s: %s (%s)", resp.Status, s3Err.Message, s3Err.Code)
+	}
+	return nil, fmt.Errorf("s3 %s", resp.Status)
+}
+
+func (s *S3Storage) objectURL(key string, query url.Values) *url.URL {
+	target := *s.endpoint
+	objectPath := ""
+	if key != "" {
+		objectPath = "/" + s.prefix + key
+	}
+
+	if s.usePathStyle {
+		target.Path = s.endpoint.Path + "/" + s.bucket + objectPath
+	} else {
+		target.Host = s.bucket + "." + s.endpoint.Host
+		target.Path = s.endpoint.Path + objectPath
+		if target.Path == "" {
+			target.Path = "/"
+		}
+	}
+	target.RawPath = ""
+	if query != nil {
+		target.RawQuery = s3CanonicalQuery(query)
+	}
+	return &target
+}
+
+// sign adds an Authorization header using AWS Signature Version 4. The
+// session token of temporary credentials is sent and signed when set.
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format(s3DateFormat)
+	scope := s.scope(now)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + s.sessionToken + "\n"
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.EscapedPath()),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, s.signature(now, amzDate, scope, canonical)))
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *S3Storage) objectInfo(key string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info
+}
+
+// s3EscapePath re-escapes a path with the stricter RFC 3986 rules SigV4
+// expects, leaving slashes alone.
+func s3EscapePath(escapedPath string) string {
+	if escapedPath == "" {
+		return "/"
+	}
+	segments := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		segments[i] = s3Escape(unescaped)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
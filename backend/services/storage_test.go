@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestStorageBackends runs the same contract against every backend.
+func TestStorageBackends(t *testing.T) {
+	backends := []struct {
+		name    string
+		new     func(t *testing.T) Storage
+		presign bool
+	}{
+		{"local", func(t *testing.T) Storage { return NewLocalStorage(t.TempDir()) }, false},
+		{"memory", func(t *testing.T) Storage { return NewMemoryStorage() }, false},
+		{"s3", func(t *testing.T) Storage { return newFakeS3Storage(t, "") }, true},
+		{"s3 with session token", func(t *testing.T) Storage { return newFakeS3Storage(t, "token") }, true},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			testStorage(t, backend.new, backend.presign)
+		})
+	}
+}
+
+func testStorage(t *testing.T, newStorage func(t *testing.T) Storage, presign bool) {
+	ctx := context.Background()
+
+	t.Run("put and get", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.Put(ctx, "a.pdf", []byte("%PDF-1.4"), "application/pdf"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		reader, info, err := s.Get(ctx, "a.pdf")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		if string(data) != "%PDF-1.4" {
+			t.Errorf("Get() = %q, want %q", data, "%PDF-1.4")
+		}
+		if info.Key != "a.pdf" || info.Size != 8 {
+			t.Errorf("Get() info = %+v, want key a.pdf and size 8", info)
+		}
+		if time.Since(info.ModTime) > time.Minute {
+			t.Errorf("ModTime = %v, want about now", info.ModTime)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		s := newStorage(t)
+		s.Put(ctx, "a.json", []byte("first"), "application/json")
+		if err := s.Put(ctx, "a.json", []byte("second!"), "application/json"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		data, err := readObject(ctx, s, "a.json")
+		if err != nil || string(data) != "second!" {
+			t.Errorf("after overwrite got %q, %v", data, err)
+		}
+		info, err := s.Stat(ctx, "a.json")
+		if err != nil || info.Size != 7 {
+			t.Errorf("Stat() = %+v, %v, want size 7", info, err)
+		}
+
+		// Same size, written within the clock's resolution.
+		s.Put(ctx, "a.json", []byte("third!!"), "application/json")
+		if again, err := s.Stat(ctx, "a.json"); err != nil || again.ETag == info.ETag {
+			t.Errorf("ETag after a same-size overwrite = %q, %v, want it to change from %q", again.ETag, err, info.ETag)
+		}
+	})
+
+	t.Run("missing objects", func(t *testing.T) {
+		s := newStorage(t)
+		if _, _, err := s.Get(ctx, "missing.pdf"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("Get() error = %v, want %v", err, ErrObjectNotFound)
+		}
+		if _, err := s.Stat(ctx, "missing.pdf"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("Stat() error = %v, want %v", err, ErrObjectNotFound)
+		}
+		if err := s.Delete(ctx, "missing.pdf"); err != nil {
+			t.Errorf("Delete() of a missing object error = %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := newStorage(t)
+		s.Put(ctx, "id/cover.png", []byte("png"), "image/png")
+		if err := s.Delete(ctx, "id/cover.png"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := s.Stat(ctx, "id/cover.png"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("Stat() after Delete error = %v, want %v", err, ErrObjectNotFound)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		s := newStorage(t)
+		for _, key := range []string{"a.pdf", "a.json", "b.pdf", "a/cover.png", "a/page-1-96dpi.png", "names/x.json"} {
+			if err := s.Put(ctx, key, []byte(key), ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			prefix string
+			want   []string
+		}{
+			{"", []string{"a.json", "a.pdf", "a/cover.png", "a/page-1-96dpi.png", "b.pdf", "names/x.json"}},
+			{"a/", []string{"a/cover.png", "a/page-1-96dpi.png"}},
+			{"a/page-", []string{"a/page-1-96dpi.png"}},
+			{"names/", []string{"names/x.json"}},
+			{"missing/", nil},
+		}
+		for _, tt := range tests {
+			objects, err := s.List(ctx, tt.prefix)
+			if err != nil {
+				t.Fatalf("List(%q) error = %v", tt.prefix, err)
+			}
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+				if object.Size != int64(len(object.Key)) {
+					t.Errorf("List(%q) size of %s = %d, want %d", tt.prefix, object.Key, object.Size, len(object.Key))
+				}
+			}
+			sort.Strings(keys)
+			if !equalStrings(keys, tt.want) {
+				t.Errorf("List(%q) = %v, want %v", tt.prefix, keys, tt.want)
+			}
+		}
+	})
+
+	t.Run("put if absent", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.PutIfAbsent(ctx, "invoices/1.json", []byte("first"), "application/json"); err != nil {
+			t.Fatalf("PutIfAbsent() error = %v", err)
+		}
+		if err := s.PutIfAbsent(ctx, "invoices/1.json", []byte("second"), "application/json"); !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("PutIfAbsent() on an existing key error = %v, want %v", err, ErrPreconditionFailed)
+		}
+		if data, err := readObject(ctx, s, "invoices/1.json"); err != nil || string(data) != "first" {
+			t.Errorf("after a lost claim got %q, %v, want the first write", data, err)
+		}
+	})
+
+	t.Run("put if absent races", func(t *testing.T) {
+		s := newStorage(t)
+		wins := raceWriters(8, func(i int) error {
+			return s.PutIfAbsent(ctx, "invoices/2.json", []byte{byte('a' + i)}, "application/json")
+		})
+		if wins != 1 {
+			t.Errorf("%d concurrent PutIfAbsent calls succeeded, want 1", wins)
+		}
+	})
+
+	t.Run("put if match", func(t *testing.T) {
+		s := newStorage(t)
+		if err := s.PutIfMatch(ctx, "links/a.json", []byte("x"), "application/json", "etag"); !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("PutIfMatch() on a missing key error = %v, want %v", err, ErrObjectNotFound)
+		}
+
+		s.Put(ctx, "links/a.json", []byte("unused"), "application/json")
+		original, err := s.Stat(ctx, "links/a.json")
+		if err != nil || original.ETag == "" {
+			t.Fatalf("Stat() = %+v, %v, want an ETag", original, err)
+		}
+		if err := s.PutIfMatch(ctx, "links/a.json", []byte("used"), "application/json", original.ETag); err != nil {
+			t.Fatalf("PutIfMatch() error = %v", err)
+		}
+		if err := s.PutIfMatch(ctx, "links/a.json", []byte("used again"), "application/json", original.ETag); !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("PutIfMatch() with a stale ETag error = %v, want %v", err, ErrPreconditionFailed)
+		}
+
+		_, info, err := s.Get(ctx, "links/a.json")
+		if err != nil || info.ETag == original.ETag {
+			t.Errorf("Get() ETag = %q, %v, want it to change from %q", info.ETag, err, original.ETag)
+		}
+		if data, _ := readObject(ctx, s, "links/a.json"); string(data) != "used" {
+			t.Errorf("after a stale write got %q, want %q", data, "used")
+		}
+	})
+
+	t.Run("put if match races", func(t *testing.T) {
+		s := newStorage(t)
+		s.Put(ctx, "links/b.json", []byte("unused"), "application/json")
+		info, err := s.Stat(ctx, "links/b.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wins := raceWriters(8, func(i int) error {
+			return s.PutIfMatch(ctx, "links/b.json", []byte{byte('a' + i)}, "application/json", info.ETag)
+		})
+		if wins != 1 {
+			t.Errorf("%d concurrent PutIfMatch calls succeeded, want 1", wins)
+		}
+	})
+
+	t.Run("presign", func(t *testing.T) {
+		s := newStorage(t)
+		url, err := s.PresignURL(ctx, "a.pdf", time.Minute)
+		if presign {
+			if err != nil || url == "" {
+				t.Errorf("PresignURL() = %q, %v", url, err)
+			}
+			return
+		}
+		if !errors.Is(err, ErrPresignNotSupported) {
+			t.Errorf("PresignURL() error = %v, want %v", err, ErrPresignNotSupported)
+		}
+	})
+}
+
+// raceWriters runs write from n goroutines at once and counts the successes.
+func raceWriters(n int, write func(i int) error) int {
+	var wg sync.WaitGroup
+	var wins atomic.Int32
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			if write(i) == nil {
+				wins.Add(1)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return int(wins.Load())
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"html/template"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/KrishKoria/Vigovia/config"
 	"github.com/KrishKoria/Vigovia/models"
@@ -16,7 +18,10 @@ import (
 
 type TemplateService struct {
 	templatePath string
-	templates    map[string]*template.Template
+
+	// mu guards templates, which concurrent first renders fill in.
+	mu        sync.RWMutex
+	templates map[string]*template.Template
 }
 
 func NewTemplateService() *TemplateService {
@@ -31,13 +36,23 @@ func (s *TemplateService) loadAndCacheTemplate(templateName string, files []stri
 		logrus.WithError(err).WithField("template", templateName).Error("Failed to parse template")
 		return nil, fmt.Errorf("failed to parse template %s: %w", templateName, err)
 	}
-	s.templates[templateName] = tmpl
+	s.mu.Lock()
+	if cached, exists := s.templates[templateName]; exists {
+		// Another render parsed it first; keep one copy.
+		tmpl = cached
+	} else {
+		s.templates[templateName] = tmpl
+	}
+	s.mu.Unlock()
 	logrus.WithField("template", templateName).WithField("fileCount", len(files)).Debug("Template loaded and cached")
 	return tmpl, nil
 }
 
 func (s *TemplateService) LoadTemplate(templateName string) (*template.Template, error) {
-	if tmpl, exists := s.templates[templateName]; exists {
+	s.mu.RLock()
+	tmpl, exists := s.templates[templateName]
+	s.mu.RUnlock()
+	if exists {
 		return tmpl, nil
 	}
 	if templateName == "base.html" {
@@ -49,6 +64,7 @@ func (s *TemplateService) LoadTemplate(templateName string) (*template.Template,
 			filepath.Join(s.templatePath, "partials", "flight-summary.html"),
 			filepath.Join(s.templatePath, "partials", "hotel-bookings.html"),
 			filepath.Join(s.templatePath, "partials", "activity-table.html"),
+			filepath.Join(s.templatePath, "partials", "cost-breakdown.html"),
 			filepath.Join(s.templatePath, "partials", "payment-plan.html"),
 			filepath.Join(s.templatePath, "partials", "inclusions.html"),
 			filepath.Join(s.templatePath, "partials", "important-notes.html"),
@@ -57,6 +73,16 @@ func (s *TemplateService) LoadTemplate(templateName string) (*template.Template,
 		}
 		return s.loadAndCacheTemplate(templateName, files)
 	}
+	if templateName == "invoice.html" {
+		files := []string{
+			filepath.Join(s.templatePath, "invoice.html"),
+			filepath.Join(s.templatePath, "partials", "invoice-header.html"),
+			filepath.Join(s.templatePath, "partials", "invoice-lines.html"),
+			filepath.Join(s.templatePath, "partials", "bank-details.html"),
+			filepath.Join(s.templatePath, "partials", "footer.html"),
+		}
+		return s.loadAndCacheTemplate(templateName, files)
+	}
 	file := filepath.Join(s.templatePath, templateName)
 	return s.loadAndCacheTemplate(templateName, []string{file})
 }
@@ -68,6 +94,14 @@ func (s *TemplateService) RenderTemplate(templateName string, data *models.Templ
 		return "", err
 	}
 	
+	// Each render gets its own clone so the currency helpers can be bound to
+	// the request's currency. The cached template is never executed itself.
+	tmpl, err = tmpl.Clone()
+	if err != nil {
+		return "", fmt.Errorf("failed to clone template %s: %w", templateName, err)
+	}
+	tmpl.Funcs(currencyFunctions(utils.CurrencyOrDefault(data.Currency, data.Locale)))
+	
 	var result strings.Builder
 	err = tmpl.Execute(&result, data)
 	if err != nil {
@@ -87,6 +121,88 @@ func (s *TemplateService) RenderTemplate(templateName string, data *models.Templ
 	return html, nil
 }
 
+// currencyFunctions format money in the request's currency. An explicit
+// ISO code, as in {{formatCurrency .Price "USD"}}, overrides it; anything
+// else, such as a literal symbol, is ignored.
+func currencyFunctions(c *utils.Currency) template.FuncMap {
+	pick := func(code []string) *utils.Currency {
+		if len(code) > 0 {
+			if other, err := utils.NewCurrency(code[0], c.Locale); err == nil {
+				return other
+			}
+		}
+		return c
+	}
+	
+	return template.FuncMap{
+		"formatCurrency": func(amount interface{}, code ...string) string {
+			if money, ok := amount.(models.Money); ok && len(code) == 0 {
+				return c.FormatMoney(money)
+			}
+			value, _ := toFloat64(amount)
+			return pick(code).Format(value)
+		},
+		// formatCurrencyString predates models.Money and still accepts
+		// amounts written as text.
+		"formatCurrencyString": func(amount interface{}, code ...string) string {
+			if text, ok := amount.(string); ok {
+				return pick(code).FormatString(text)
+			}
+			if money, ok := amount.(models.Money); ok && len(code) == 0 {
+				return c.FormatMoney(money)
+			}
+			value, _ := toFloat64(amount)
+			return pick(code).Format(value)
+		},
+		"formatNumber": func(amount interface{}) string {
+			value, _ := toFloat64(amount)
+			return c.FormatNumber(value)
+		},
+		// amountInWords takes "en" or "hi" and "indian" or "international"
+		// in any order; see utils.AmountInWords for the defaults.
+		"amountInWords": func(amount interface{}, options ...string) string {
+			var lang, system string
+			for _, option := range options {
+				switch option {
+				case utils.WordsEnglish, utils.WordsHindi:
+					lang = option
+				case utils.NumberingIndian, utils.NumberingInternational:
+					system = option
+				}
+			}
+			money, ok := toMoney(amount, c)
+			if !ok {
+				return ""
+			}
+			return utils.AmountInWords(money, c, lang, system)
+		},
+		"currencySymbol": func() string {
+			return c.Symbol
+		},
+		"currencyCode": func() string {
+			return c.Code
+		},
+	}
+}
+
+// toMoney reads a template value as an exact amount. Floats are first
+// rounded to the currency's decimal places.
+func toMoney(v interface{}, c *utils.Currency) (models.Money, bool) {
+	switch v := v.(type) {
+	case models.Money:
+		return v, v.IsSet() && v.Valid()
+	case string:
+		money, err := models.ParseMoney(v)
+		return money, err == nil
+	}
+	value, ok := toFloat64(v)
+	if !ok {
+		return models.Money{}, false
+	}
+	money, err := models.ParseMoney(strconv.FormatFloat(value, 'f', c.Decimals, 64))
+	return money, err == nil
+}
+
 func toFloat64(v interface{}) (float64, bool) {
 	switch v := v.(type) {
 	case float64:
@@ -99,15 +215,15 @@ func toFloat64(v interface{}) (float64, bool) {
 		return float64(v), true
 	case int32:
 		return float64(v), true
+	case models.Money:
+		return v.Float64(), true
 	default:
 		return 0, false
 	}
 }
 
 func (s *TemplateService) getTemplateFunctions() template.FuncMap {
-	return template.FuncMap{
-		"formatCurrency": utils.FormatCurrency,
-		"formatCurrencyString": utils.FormatCurrencyString,
+	funcs := template.FuncMap{
 		"formatDate":     utils.FormatDate,
 		"formatTime":     utils.FormatTime,
 		"timeRange":      utils.FormatTimeRange,
@@ -184,4 +300,11 @@ func (s *TemplateService) getTemplateFunctions() template.FuncMap {
 			return dict
 		},
 	}
+	
+	// Parsing needs the currency helpers defined; renders rebind them.
+	defaults := config.AppConfig.PDF
+	for name, fn := range currencyFunctions(utils.CurrencyOrDefault(defaults.Currency, defaults.Locale)) {
+		funcs[name] = fn
+	}
+	return funcs
 }
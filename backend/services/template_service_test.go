@@ -0,0 +1,44 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestRenderTemplateUsesRequestCurrency(t *testing.T) {
+	dir := t.TempDir()
+	page := `{{formatCurrency 150000}}|{{formatCurrencyString "1500.4"}}|{{formatCurrencyString "on request"}}|{{formatCurrency 99.5 "USD"}}|{{currencyCode}}`
+	if err := os.WriteFile(filepath.Join(dir, "prices.html"), []byte(page), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTemplateService()
+	s.templatePath = dir
+
+	tests := []struct {
+		name     string
+		currency string
+		locale   string
+		want     string
+	}{
+		{"rupees", "INR", "", "₹1,50,000.00|₹1,500.40|₹on request|US$99.50|INR"},
+		{"yen", "JPY", "", "¥150,000|¥1,500|¥on request|$99.50|JPY"},
+		{"euros in germany", "EUR", "de-DE", "€150.000,00|€1.500,40|€on request|$99,50|EUR"},
+		{"default", "", "", "₹1,50,000.00|₹1,500.40|₹on request|US$99.50|INR"},
+	}
+
+	// One cached template serves every request in turn.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := s.RenderTemplate("prices.html", &models.TemplateData{Currency: tt.currency, Locale: tt.locale})
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %v", err)
+			}
+			if html != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", html, tt.want)
+			}
+		})
+	}
+}
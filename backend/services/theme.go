@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/sirupsen/logrus"
+)
+
+// vigoviaTheme is the default palette every partial falls back to
+var vigoviaTheme = models.Theme{
+	PrimaryColor:     "#321e5d",
+	AccentColor:      "#680099",
+	AccentLightColor: "#936fe0",
+	AccentFaintColor: "#6800991a",
+	HighlightColor:   "#4a90e2",
+	SurfaceColor:     "#f9eeff",
+	BorderColor:      "#e5d3f0",
+	LogoURL:          "/static/final-logo-2.png",
+	CompanyName:      "Vigovia",
+}
+
+// vigoviaCompany is printed in the footer when a request has no companyInfo
+var vigoviaCompany = models.CompanyInfo{
+	Name: "Vigovia Tech Pvt. Ltd",
+	RegisteredOffice: models.RegisteredOffice{
+		Address: "Hd-109 Cinnabar Hills, Links Business Park",
+		City:    "Karnataka",
+		State:   "Karnataka",
+		Country: "India",
+	},
+	Contact: models.ContactInfo{
+		Phone: "+91-99X9999999",
+		Email: "Contact@Vigovia.Com",
+	},
+	Logo: "/static/final-logo-2.png",
+}
+
+type rgbColor struct {
+	r, g, b uint8
+}
+
+// buildTheme turns CustomBranding into the CSS variables consumed by the
+// partials. Only the primary and accent colors are supplied by partners; the
+// lighter tints are derived from them so the document stays consistent. A
+// white-label theme never shows the Vigovia logo or company details.
+func buildTheme(branding models.CustomBranding, company models.CompanyInfo) models.Theme {
+	theme := brandPalette(branding, company)
+	if theme.WhiteLabel && theme.LogoURL == vigoviaTheme.LogoURL {
+		theme.LogoURL = ""
+	}
+	theme.ShowCompanyInfo = !theme.WhiteLabel || company.Name != vigoviaCompany.Name
+	return theme
+}
+
+func brandPalette(branding models.CustomBranding, company models.CompanyInfo) models.Theme {
+	theme := vigoviaTheme
+
+	if company.Logo != "" {
+		theme.LogoURL = company.Logo
+	}
+	if branding.LogoURL != "" {
+		theme.LogoURL = branding.LogoURL
+	}
+	if branding.CompanyName != "" {
+		theme.CompanyName = branding.CompanyName
+		theme.WhiteLabel = true
+	}
+
+	primary, primaryErr := parseHexColor(branding.PrimaryColor)
+	accent, accentErr := parseHexColor(branding.AccentColor)
+
+	if branding.PrimaryColor != "" && primaryErr != nil {
+		logrus.WithError(primaryErr).Warn("Ignoring invalid primary branding color")
+	}
+	if branding.AccentColor != "" && accentErr != nil {
+		logrus.WithError(accentErr).Warn("Ignoring invalid accent branding color")
+	}
+
+	hasPrimary := branding.PrimaryColor != "" && primaryErr == nil
+	hasAccent := branding.AccentColor != "" && accentErr == nil
+	if !hasPrimary && !hasAccent {
+		return theme
+	}
+
+	switch {
+	case hasPrimary && !hasAccent:
+		accent = primary.mix(rgbColor{255, 255, 255}, 0.25)
+	case hasAccent && !hasPrimary:
+		primary = accent.mix(rgbColor{0, 0, 0}, 0.45)
+	}
+
+	theme.PrimaryColor = primary.hex()
+	theme.AccentColor = accent.hex()
+	theme.AccentLightColor = accent.mix(rgbColor{255, 255, 255}, 0.4).hex()
+	theme.AccentFaintColor = accent.hex() + "1a"
+	theme.HighlightColor = theme.AccentLightColor
+	theme.SurfaceColor = accent.mix(rgbColor{255, 255, 255}, 0.93).hex()
+	theme.BorderColor = accent.mix(rgbColor{255, 255, 255}, 0.8).hex()
+	theme.WhiteLabel = true
+
+	return theme
+}
+
+// parseHexColor reads a #rgb, #rgba, #rrggbb or #rrggbbaa color, the forms
+// the hexcolor validator accepts. Alpha is dropped: brand colors are printed
+// opaque and their tints are derived against white.
+func parseHexColor(value string) (rgbColor, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		short := hex
+		hex = ""
+		for i := 0; i < len(short); i++ {
+			hex += string([]byte{short[i], short[i]})
+		}
+	}
+	if len(hex) == 8 {
+		hex = hex[:6]
+	}
+	if len(hex) != 6 {
+		return rgbColor{}, fmt.Errorf("invalid hex color %q", value)
+	}
+
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgbColor{}, fmt.Errorf("invalid hex color %q", value)
+	}
+
+	return rgbColor{r: uint8(n >> 16), g: uint8(n >> 8), b: uint8(n)}, nil
+}
+
+// mix blends c towards other by weight (0 keeps c, 1 returns other)
+func (c rgbColor) mix(other rgbColor, weight float64) rgbColor {
+	blend := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*weight + 0.5)
+	}
+	return rgbColor{
+		r: blend(c.r, other.r),
+		g: blend(c.g, other.g),
+		b: blend(c.b, other.b),
+	}
+}
+
+func (c rgbColor) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
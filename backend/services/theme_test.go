@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rgbColor
+		wantErr bool
+	}{
+		{"#336699", rgbColor{0x33, 0x66, 0x99}, false},
+		{"336699", rgbColor{0x33, 0x66, 0x99}, false},
+		{"#369", rgbColor{0x33, 0x66, 0x99}, false},
+		{"#369f", rgbColor{0x33, 0x66, 0x99}, false},
+		{"#33669980", rgbColor{0x33, 0x66, 0x99}, false},
+		{" #ABCDEF ", rgbColor{0xab, 0xcd, 0xef}, false},
+		{"", rgbColor{}, true},
+		{"#12", rgbColor{}, true},
+		{"#12345", rgbColor{}, true},
+		{"#1234567", rgbColor{}, true},
+		{"#ggg", rgbColor{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseHexColor(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHexColor(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseHexColor(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTheme(t *testing.T) {
+	partner := models.CompanyInfo{Name: "Partner Travels", Logo: "/static/partner.png"}
+
+	tests := []struct {
+		name            string
+		branding        models.CustomBranding
+		company         models.CompanyInfo
+		wantWhiteLabel  bool
+		wantLogo        string
+		wantCompanyInfo bool
+		wantPrimary     string
+	}{
+		{"default", models.CustomBranding{}, vigoviaCompany, false, vigoviaTheme.LogoURL, true, vigoviaTheme.PrimaryColor},
+		{"company name only", models.CustomBranding{CompanyName: "Acme Tours"}, vigoviaCompany, true, "", false, vigoviaTheme.PrimaryColor},
+		{"colors only", models.CustomBranding{PrimaryColor: "#112233"}, vigoviaCompany, true, "", false, "#112233"},
+		{"eight digit color", models.CustomBranding{AccentColor: "#11223380", PrimaryColor: "#445566ff"}, vigoviaCompany, true, "", false, "#445566"},
+		{"own logo", models.CustomBranding{CompanyName: "Acme Tours", LogoURL: "https://acme.example/logo.png"}, vigoviaCompany, true, "https://acme.example/logo.png", false, vigoviaTheme.PrimaryColor},
+		{"own company", models.CustomBranding{CompanyName: "Partner Travels"}, partner, true, "/static/partner.png", true, vigoviaTheme.PrimaryColor},
+		{"invalid color", models.CustomBranding{PrimaryColor: "#12"}, vigoviaCompany, false, vigoviaTheme.LogoURL, true, vigoviaTheme.PrimaryColor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme := buildTheme(tt.branding, tt.company)
+			if theme.WhiteLabel != tt.wantWhiteLabel {
+				t.Errorf("WhiteLabel = %v, want %v", theme.WhiteLabel, tt.wantWhiteLabel)
+			}
+			if theme.LogoURL != tt.wantLogo {
+				t.Errorf("LogoURL = %q, want %q", theme.LogoURL, tt.wantLogo)
+			}
+			if theme.ShowCompanyInfo != tt.wantCompanyInfo {
+				t.Errorf("ShowCompanyInfo = %v, want %v", theme.ShowCompanyInfo, tt.wantCompanyInfo)
+			}
+			if theme.PrimaryColor != tt.wantPrimary {
+				t.Errorf("PrimaryColor = %q, want %q", theme.PrimaryColor, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestFooterWhiteLabel(t *testing.T) {
+	s := newTestPDFService(t)
+
+	tests := []struct {
+		name       string
+		branding   models.CustomBranding
+		wantBrand  bool
+		wantFooter string
+	}{
+		{"vigovia", models.CustomBranding{}, true, "Vigovia Tech Pvt. Ltd"},
+		{"white label", models.CustomBranding{CompanyName: "Acme Tours"}, false, "Acme Tours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := loadSample(t, "test_sample.json")
+			request.CompanyInfo = models.CompanyInfo{}
+			request.Config.CustomBranding = tt.branding
+
+			html, _, err := s.renderHTML(request, "")
+			if err != nil {
+				t.Fatalf("renderHTML() error = %v", err)
+			}
+			footer := html[strings.Index(html, `<footer class="footer-content">`):]
+			footer = footer[:strings.Index(footer, "</footer>")]
+
+			for _, vigovia := range []string{vigoviaCompany.Name, vigoviaCompany.Contact.Email, vigoviaTheme.LogoURL} {
+				if got := strings.Contains(footer, vigovia); got != tt.wantBrand {
+					t.Errorf("footer contains %q = %v, want %v", vigovia, got, tt.wantBrand)
+				}
+			}
+			if !strings.Contains(footer, tt.wantFooter) {
+				t.Errorf("footer does not contain %q", tt.wantFooter)
+			}
+		})
+	}
+}
+
+func TestHeaderLogo(t *testing.T) {
+	const vigoviaPlaceholder = "PLAN.PACK.GO"
+
+	tests := []struct {
+		name         string
+		branding     models.CustomBranding
+		documentType string
+		want         []string
+		notWant      []string
+	}{
+		{
+			name:    "vigovia logo by default",
+			want:    []string{`src="/static/final-logo-2.png"`},
+			notWant: []string{vigoviaPlaceholder},
+		},
+		{
+			name:     "partner logo",
+			branding: models.CustomBranding{CompanyName: "Acme Tours", LogoURL: "https://acme.example/logo.png"},
+			want:     []string{`src="https://acme.example/logo.png"`, `alt="Acme Tours"`},
+			notWant:  []string{"final-logo-2.png", vigoviaPlaceholder},
+		},
+		{
+			name:     "white label without a logo",
+			branding: models.CustomBranding{CompanyName: "Acme Tours", LogoURL: "/static/final-logo-2.png"},
+			want:     []string{`<span class="logo-text">Acme Tours</span>`},
+			notWant:  []string{"final-logo-2.png", vigoviaPlaceholder},
+		},
+		{
+			name:         "partner logo on an invoice",
+			branding:     models.CustomBranding{CompanyName: "Acme Tours", LogoURL: "https://acme.example/logo.png"},
+			documentType: models.DocumentProformaInvoice,
+			want:         []string{`src="https://acme.example/logo.png"`},
+			notWant:      []string{"final-logo-2.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPDFService(t)
+			request := loadSample(t, "test_sample.json")
+			request.Config.CustomBranding = tt.branding
+			if tt.documentType != "" {
+				request.DocumentType = tt.documentType
+				request.Invoice = &models.InvoiceOptions{SellerGSTIN: testSellerGSTIN}
+			}
+
+			doc, err := s.prepareDocument(context.Background(), request)
+			if err != nil {
+				t.Fatalf("prepareDocument() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(doc.HTML, want) {
+					t.Errorf("header is missing %s", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(doc.HTML, notWant) {
+					t.Errorf("header unexpectedly contains %s", notWant)
+				}
+			}
+		})
+	}
+}
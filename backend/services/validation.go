@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/KrishKoria/Vigovia/utils"
+)
+
+// ValidationError lists every field-level problem found in a request, along
+// with any warnings so they can be fixed in the same pass.
+type ValidationError struct {
+	Errors   []models.APIError
+	Warnings []models.APIError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("validation failed: %s: %s", e.Errors[0].Field, e.Errors[0].Message)
+	}
+	return fmt.Sprintf("validation failed: %d errors", len(e.Errors))
+}
+
+// fieldError ties a configuration error to the request field that caused it.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// Validate runs every check that does not need a browser against the request
+// with derived fields filled in. problems block rendering; warnings come from
+// business rules set to "warning" and do not.
+func (s *PDFService) Validate(request *models.ItineraryRequest) (problems, warnings []models.APIError) {
+	derived, conflicts := deriveFields(request)
+	request = derived
+
+	// Pricing and the payment schedule run first so a computed total and
+	// generated installments satisfy the required payment fields.
+	pricing, pricingConflicts, pricingErr := s.pricing.Apply(request)
+	conflicts = append(conflicts, pricingConflicts...)
+	var scheduleErr error
+	if pricingErr == nil {
+		scheduleErr = s.payments.Apply(request)
+	}
+
+	problems = utils.ValidateStruct(request)
+
+	for _, problem := range checkMoney(request) {
+		problems = appendProblem(problems, problem)
+	}
+
+	if pricingErr != nil {
+		problems = appendProblem(problems, configError(pricingErr, "pricing", "INVALID_PRICING"))
+	}
+	if scheduleErr != nil {
+		// The installments are only missing because the schedule could not
+		// be generated; report the schedule alone.
+		problems = removeProblem(problems, "payment.installments")
+		problems = appendProblem(problems, configError(scheduleErr, "payment.schedule", "INVALID_SCHEDULE"))
+	}
+
+	if request.DocumentType == models.DocumentProformaInvoice && pricingErr == nil {
+		if _, err := s.invoices.Build(request, pricing, request.CompanyInfo, "", time.Now()); err != nil {
+			problems = appendProblem(problems, configError(err, "invoice", "INVALID_INVOICE"))
+		}
+	}
+
+	if _, err := resolvePageLayout(request.Config); err != nil {
+		problems = appendProblem(problems, configError(err, "config", "INVALID_PAGE_LAYOUT"))
+	}
+
+	if _, err := resolveImageOptions(request.Config.Images); err != nil {
+		problems = appendProblem(problems, configError(err, "config.images", "INVALID_IMAGE_OPTIONS"))
+	}
+
+	conflictProblems, warnings := s.ruleEngine.Conflicts(conflicts)
+	ruleProblems, ruleWarnings := s.ruleEngine.Check(request)
+	for _, problem := range append(conflictProblems, ruleProblems...) {
+		problems = appendProblem(problems, problem)
+	}
+	warnings = append(warnings, ruleWarnings...)
+
+	return problems, warnings
+}
+
+// appendProblem adds a problem unless its field was already reported, so a
+// value rejected by more than one check is listed once.
+func appendProblem(problems []models.APIError, problem models.APIError) []models.APIError {
+	for _, existing := range problems {
+		if existing.Field == problem.Field {
+			return problems
+		}
+	}
+	return append(problems, problem)
+}
+
+func removeProblem(problems []models.APIError, field string) []models.APIError {
+	kept := problems[:0]
+	for _, problem := range problems {
+		if problem.Field != field {
+			kept = append(kept, problem)
+		}
+	}
+	return kept
+}
+
+func configError(err error, field, code string) models.APIError {
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		field = fe.field
+	}
+	return models.APIError{
+		Field:   field,
+		Message: err.Error(),
+		Code:    code,
+	}
+}
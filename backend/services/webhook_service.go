@@ -0,0 +1,364 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SignatureHeader = "X-Vigovia-Signature"
+	TimestampHeader = "X-Vigovia-Timestamp"
+	DeliveryHeader  = "X-Vigovia-Delivery"
+	EventHeader     = "X-Vigovia-Event"
+)
+
+var (
+	ErrWebhooksUnsigned    = errors.New("callbacks are disabled until webhooks.secret is set")
+	ErrCallbackNotAllowed  = errors.New("callback URL is not allowed")
+	errBlockedCallbackAddr = errors.New("callback address is not public")
+)
+
+// WebhookService posts signed job results to callback URLs, retrying failed
+// deliveries with exponential backoff and keeping a bounded delivery log.
+type WebhookService struct {
+	cfg    config.WebhooksConfig
+	client *http.Client
+	wg     sync.WaitGroup
+	stop   chan struct{}
+
+	mu         sync.RWMutex
+	closed     bool
+	deliveries map[string]*models.WebhookDelivery
+	order      []string
+}
+
+// NewWebhookService fails when webhooks.api_keys are configured without
+// webhooks.secret, since receivers could not authenticate the deliveries.
+func NewWebhookService() (*WebhookService, error) {
+	cfg := config.AppConfig.Webhooks
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	// Receivers can only authenticate signed deliveries.
+	if cfg.Secret == "" {
+		if len(cfg.APIKeys) > 0 {
+			return nil, errors.New("webhooks.api_keys are configured but webhooks.secret is not set")
+		}
+		logrus.Warn("webhooks.secret is not set, callbacks are disabled")
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		// Checked on every connection, so a callback host cannot resolve to
+		// a public address when validated and a private one when delivered.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedCallbackIP(ip) {
+				return fmt.Errorf("%w: %s", errBlockedCallbackAddr, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookService{
+		cfg:        cfg,
+		client:     &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		stop:       make(chan struct{}),
+		deliveries: make(map[string]*models.WebhookDelivery),
+	}, nil
+}
+
+// CheckCallback rejects callbacks that cannot be signed and callback URLs
+// that are not http(s) or resolve to loopback, private, link-local or other
+// non-public addresses, unless webhooks.allow_private_targets is set.
+// Failures wrap ErrWebhooksUnsigned or ErrCallbackNotAllowed.
+func (s *WebhookService) CheckCallback(ctx context.Context, callbackURL string) error {
+	if s.cfg.Secret == "" {
+		return ErrWebhooksUnsigned
+	}
+
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q must be an absolute http or https URL", ErrCallbackNotAllowed, callbackURL)
+	}
+	if s.cfg.AllowPrivateTargets {
+		return nil
+	}
+
+	host := u.Hostname()
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return fmt.Errorf("%w: cannot resolve %s", ErrCallbackNotAllowed, host)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if blockedCallbackIP(ip) {
+			return fmt.Errorf("%w: %s resolves to a non-public address", ErrCallbackNotAllowed, host)
+		}
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// net.IP.IsPrivate does not cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func blockedCallbackIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// CallbackForAPIKey returns the callback registered for an API key, if any.
+func (s *WebhookService) CallbackForAPIKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	for _, entry := range s.cfg.APIKeys {
+		if hmac.Equal([]byte(entry.Key), []byte(apiKey)) {
+			return entry.CallbackURL
+		}
+	}
+	return ""
+}
+
+// Dispatch delivers the payload in the background. Progress is recorded in
+// the delivery log.
+func (s *WebhookService) Dispatch(url string, payload models.WebhookPayload) {
+	if s.cfg.Secret == "" {
+		logrus.WithField("jobID", payload.JobID).Warn("Webhook secret not set, dropping delivery")
+		return
+	}
+
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.NewString(),
+		JobID:     payload.JobID,
+		Event:     payload.Event,
+		URL:       url,
+		Status:    models.WebhookStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		logrus.WithField("jobID", payload.JobID).Warn("Webhook service closed, dropping delivery")
+		return
+	}
+	s.recordLocked(delivery)
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		s.deliver(delivery, payload)
+	}()
+}
+
+func (s *WebhookService) deliver(delivery *models.WebhookDelivery, payload models.WebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logrus.WithError(err).WithField("deliveryID", delivery.ID).Error("Failed to encode webhook payload")
+		s.finish(delivery, models.WebhookStatusFailed)
+		return
+	}
+
+	backoff := s.cfg.InitialBackoff
+	for attempt := 1; attempt <= s.cfg.MaxAttempts; attempt++ {
+		result := s.attempt(delivery, attempt, body)
+		s.mu.Lock()
+		delivery.Attempts = append(delivery.Attempts, result)
+		s.mu.Unlock()
+
+		entry := logrus.WithFields(logrus.Fields{
+			"deliveryID": delivery.ID,
+			"jobID":      delivery.JobID,
+			"attempt":    attempt,
+			"statusCode": result.StatusCode,
+		})
+
+		if result.Error == "" && result.StatusCode >= 200 && result.StatusCode < 300 {
+			entry.Info("Webhook delivered")
+			s.finish(delivery, models.WebhookStatusDelivered)
+			return
+		}
+		if !retryableWebhookStatus(result.StatusCode) {
+			entry.WithField("error", result.Error).Warn("Webhook rejected, not retrying")
+			break
+		}
+		if attempt == s.cfg.MaxAttempts {
+			entry.WithField("error", result.Error).Warn("Webhook failed, attempts exhausted")
+			break
+		}
+
+		entry.WithField("error", result.Error).WithField("retryIn", backoff.String()).Warn("Webhook failed, retrying")
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+			entry.Warn("Webhook retries abandoned on shutdown")
+			s.finish(delivery, models.WebhookStatusFailed)
+			return
+		}
+
+		backoff *= 2
+		if s.cfg.MaxBackoff > 0 && backoff > s.cfg.MaxBackoff {
+			backoff = s.cfg.MaxBackoff
+		}
+	}
+
+	s.finish(delivery, models.WebhookStatusFailed)
+}
+
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery, number int, body []byte) models.WebhookAttempt {
+	started := time.Now()
+	result := models.WebhookAttempt{
+		Number:      number,
+		AttemptedAt: started,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vigovia-Webhooks/1.0")
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+SignWebhook(s.cfg.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	result.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Error = fmt.Sprintf("unexpected status %s", resp.Status)
+	}
+	return result
+}
+
+// SignWebhook computes the hex HMAC-SHA256 of "timestamp.body". Receivers
+// recompute it with the shared secret to authenticate a delivery.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryableWebhookStatus treats transport errors (status 0), timeouts, rate
+// limits and server errors as transient. Other client errors are final.
+func retryableWebhookStatus(status int) bool {
+	return status == 0 ||
+		status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests ||
+		status >= 500
+}
+
+func (s *WebhookService) finish(delivery *models.WebhookDelivery, status string) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery.Status = status
+	delivery.CompletedAt = &now
+}
+
+// recordLocked adds a delivery to the log, evicting the oldest entry once
+// the configured log size is reached.
+func (s *WebhookService) recordLocked(delivery *models.WebhookDelivery) {
+	s.deliveries[delivery.ID] = delivery
+	s.order = append(s.order, delivery.ID)
+	if s.cfg.LogSize > 0 && len(s.order) > s.cfg.LogSize {
+		delete(s.deliveries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// DeliveriesForJob returns snapshots of every logged delivery for a job.
+func (s *WebhookService) DeliveriesForJob(jobID string) []models.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, id := range s.order {
+		delivery := s.deliveries[id]
+		if delivery.JobID != jobID {
+			continue
+		}
+		snapshot := *delivery
+		snapshot.Attempts = append([]models.WebhookAttempt(nil), delivery.Attempts...)
+		deliveries = append(deliveries, snapshot)
+	}
+	return deliveries
+}
+
+// Close abandons pending retries and waits for in-flight attempts.
+func (s *WebhookService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook deliveries did not finish: %w", ctx.Err())
+	}
+}
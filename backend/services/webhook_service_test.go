@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KrishKoria/Vigovia/config"
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+const testWebhookSecret = "topsecret"
+
+func newTestWebhookService(t *testing.T, cfg config.WebhooksConfig) *WebhookService {
+	t.Helper()
+
+	saved := config.AppConfig.Webhooks
+	config.AppConfig.Webhooks = cfg
+	s, err := NewWebhookService()
+	config.AppConfig.Webhooks = saved
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { s.Close(context.Background()) })
+	return s
+}
+
+func waitForDelivery(t *testing.T, s *WebhookService, jobID string) models.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries := s.DeliveriesForJob(jobID)
+		if len(deliveries) == 1 && deliveries[0].Status != models.WebhookStatusPending {
+			return deliveries[0]
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("delivery for job %s did not finish", jobID)
+	return models.WebhookDelivery{}
+}
+
+func TestNewWebhookService(t *testing.T) {
+	apiKeys := []config.APIKeyCallback{{Key: "partner", CallbackURL: "https://partner.example/hooks"}}
+
+	tests := []struct {
+		name    string
+		cfg     config.WebhooksConfig
+		wantErr bool
+	}{
+		{"unsigned without api keys", config.WebhooksConfig{}, false},
+		{"signed with api keys", config.WebhooksConfig{Secret: testWebhookSecret, APIKeys: apiKeys}, false},
+		{"api keys without a secret", config.WebhooksConfig{APIKeys: apiKeys}, true},
+	}
+
+	saved := config.AppConfig.Webhooks
+	t.Cleanup(func() { config.AppConfig.Webhooks = saved })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig.Webhooks = tt.cfg
+			s, err := NewWebhookService()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWebhookService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if s != nil {
+				s.Close(context.Background())
+			}
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{testWebhookSecret, "1700000000", `{"event":"pdf.completed"}`, "0a4037db00d4bcba345da6dd41e8489b3f92873557b3bfeff7725f4b3e954d4d"},
+		{"key", "0", "", "85841b4efc3cd7776c3c8f9b7cca9e281c550e5d19889d78e9e669c6337f000d"},
+	}
+
+	for _, tt := range tests {
+		if got := SignWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("SignWebhook(%q, %q, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		allowPrivate bool
+		url          string
+		wantErr      error
+	}{
+		{"public address", testWebhookSecret, false, "https://93.184.216.34/hooks", nil},
+		{"no secret", "", false, "https://93.184.216.34/hooks", ErrWebhooksUnsigned},
+		{"not http", testWebhookSecret, false, "ftp://93.184.216.34/hooks", ErrCallbackNotAllowed},
+		{"relative", testWebhookSecret, false, "/hooks", ErrCallbackNotAllowed},
+		{"loopback", testWebhookSecret, false, "http://127.0.0.1:8080/hooks", ErrCallbackNotAllowed},
+		{"localhost", testWebhookSecret, false, "http://localhost/hooks", ErrCallbackNotAllowed},
+		{"ipv6 loopback", testWebhookSecret, false, "http://[::1]/hooks", ErrCallbackNotAllowed},
+		{"private", testWebhookSecret, false, "http://10.0.0.5/hooks", ErrCallbackNotAllowed},
+		{"metadata service", testWebhookSecret, false, "http://169.254.169.254/latest", ErrCallbackNotAllowed},
+		{"cgnat", testWebhookSecret, false, "http://100.64.1.1/hooks", ErrCallbackNotAllowed},
+		{"unspecified", testWebhookSecret, false, "http://0.0.0.0/hooks", ErrCallbackNotAllowed},
+		{"mapped loopback", testWebhookSecret, false, "http://[::ffff:127.0.0.1]/hooks", ErrCallbackNotAllowed},
+		{"private allowed", testWebhookSecret, true, "http://127.0.0.1:8080/hooks", nil},
+		{"allowed still needs http", testWebhookSecret, true, "file:///etc/passwd", ErrCallbackNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestWebhookService(t, config.WebhooksConfig{Secret: tt.secret, AllowPrivateTargets: tt.allowPrivate})
+
+			err := s.CheckCallback(context.Background(), tt.url)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CheckCallback(%q) error = %v", tt.url, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckCallback(%q) error = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []int
+		maxAttempts  int
+		wantStatus   string
+		wantAttempts int
+	}{
+		{"delivered", []int{http.StatusOK}, 3, models.WebhookStatusDelivered, 1},
+		{"retried until delivered", []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}, 5, models.WebhookStatusDelivered, 3},
+		{"attempts exhausted", []int{http.StatusServiceUnavailable}, 3, models.WebhookStatusFailed, 3},
+		{"rejected", []int{http.StatusBadRequest}, 3, models.WebhookStatusFailed, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls int
+			var badSignatures int
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				want := "sha256=" + SignWebhook(testWebhookSecret, r.Header.Get(TimestampHeader), body)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if !hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(want)) {
+					badSignatures++
+				}
+				status := tt.responses[len(tt.responses)-1]
+				if calls < len(tt.responses) {
+					status = tt.responses[calls]
+				}
+				calls++
+				w.WriteHeader(status)
+			}))
+			defer receiver.Close()
+
+			backoff := 5 * time.Millisecond
+			s := newTestWebhookService(t, config.WebhooksConfig{
+				Secret:              testWebhookSecret,
+				Timeout:             time.Second,
+				MaxAttempts:         tt.maxAttempts,
+				InitialBackoff:      backoff,
+				MaxBackoff:          time.Second,
+				LogSize:             10,
+				AllowPrivateTargets: true,
+			})
+
+			s.Dispatch(receiver.URL, models.WebhookPayload{Event: models.WebhookEventCompleted, JobID: tt.name})
+			delivery := waitForDelivery(t, s, tt.name)
+
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", delivery.Status, tt.wantStatus)
+			}
+			if len(delivery.Attempts) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(delivery.Attempts), tt.wantAttempts)
+			}
+			for i, attempt := range delivery.Attempts {
+				if attempt.Number != i+1 {
+					t.Errorf("attempt %d has Number %d", i, attempt.Number)
+				}
+				if i == 0 {
+					continue
+				}
+				// The backoff doubles after each failed attempt.
+				gap := attempt.AttemptedAt.Sub(delivery.Attempts[i-1].AttemptedAt)
+				if want := backoff << (i - 1); gap < want {
+					t.Errorf("attempt %d came %v after the previous one, want at least %v", i+1, gap, want)
+				}
+			}
+			if delivery.CompletedAt == nil {
+				t.Error("CompletedAt not set")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if calls != tt.wantAttempts {
+				t.Errorf("receiver got %d calls, want %d", calls, tt.wantAttempts)
+			}
+			if badSignatures > 0 {
+				t.Errorf("%d deliveries had a bad signature", badSignatures)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryBlocksPrivateTargets(t *testing.T) {
+	var calls int
+	var mu sync.Mutex
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	s := newTestWebhookService(t, config.WebhooksConfig{
+		Secret:         testWebhookSecret,
+		Timeout:        time.Second,
+		MaxAttempts:    1,
+		InitialBackoff: time.Millisecond,
+	})
+
+	s.Dispatch(receiver.URL, models.WebhookPayload{Event: models.WebhookEventCompleted, JobID: "private"})
+	delivery := waitForDelivery(t, s, "private")
+
+	if delivery.Status != models.WebhookStatusFailed {
+		t.Errorf("Status = %q, want %q", delivery.Status, models.WebhookStatusFailed)
+	}
+	if len(delivery.Attempts) != 1 || !strings.Contains(delivery.Attempts[0].Error, errBlockedCallbackAddr.Error()) {
+		t.Errorf("attempts = %+v, want one blocked attempt", delivery.Attempts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 0 {
+		t.Errorf("receiver got %d calls, want none", calls)
+	}
+}
+
+func TestWebhookDeliveryLogSize(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	s := newTestWebhookService(t, config.WebhooksConfig{
+		Secret:              testWebhookSecret,
+		Timeout:             time.Second,
+		MaxAttempts:         1,
+		LogSize:             2,
+		AllowPrivateTargets: true,
+	})
+
+	for _, job := range []string{"a", "b", "c"} {
+		s.Dispatch(receiver.URL, models.WebhookPayload{Event: models.WebhookEventCompleted, JobID: job})
+		waitForDelivery(t, s, job)
+	}
+
+	if got := s.DeliveriesForJob("a"); len(got) != 0 {
+		t.Errorf("oldest delivery still logged: %+v", got)
+	}
+	for _, job := range []string{"b", "c"} {
+		if got := s.DeliveriesForJob(job); len(got) != 1 {
+			t.Errorf("DeliveriesForJob(%q) = %d deliveries, want 1", job, len(got))
+		}
+	}
+}
+
+func TestJobServiceRejectsCallbacks(t *testing.T) {
+	pdfService := newTestPDFService(t)
+	webhooks := newTestWebhookService(t, config.WebhooksConfig{Secret: testWebhookSecret})
+	s := NewJobService(pdfService, webhooks)
+	t.Cleanup(func() { s.Close(context.Background()) })
+
+	_, err := s.Submit(context.Background(), loadSample(t, "test_sample.json"), "http://127.0.0.1/hooks")
+	if !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Errorf("Submit() error = %v, want %v", err, ErrCallbackNotAllowed)
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// DefaultCurrency is used when neither the request nor the configuration
+// names a currency.
+const DefaultCurrency = "INR"
+
+// defaultLocales picks a locale for currencies whose customers expect their
+// own digit grouping when no locale is given, such as lakh and crore for
+// rupees (₹1,50,000).
+var defaultLocales = map[string]string{
+	"INR": "en-IN",
+}
+
+// Currency formats amounts of one currency with a locale's digit grouping
+// and decimal separator.
+type Currency struct {
+	Code     string
+	Symbol   string
+	Decimals int
+	Locale   string
+
+	printer *message.Printer
+}
+
+// NewCurrency resolves an ISO 4217 code such as INR, USD or JPY. An empty
+// locale uses the currency's customary locale, or English.
+func NewCurrency(code, locale string) (*Currency, error) {
+	if code == "" {
+		code = DefaultCurrency
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return nil, fmt.Errorf("unknown currency %q", code)
+	}
+
+	if locale == "" {
+		locale = defaultLocales[unit.String()]
+	}
+	tag := language.English
+	if locale != "" {
+		tag, err = language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("unknown locale %q", locale)
+		}
+	}
+
+	printer := message.NewPrinter(tag)
+	decimals, _ := currency.Standard.Rounding(unit)
+
+	return &Currency{
+		Code:     unit.String(),
+		Symbol:   printer.Sprint(currency.Symbol(unit)),
+		Decimals: decimals,
+		Locale:   tag.String(),
+		printer:  printer,
+	}, nil
+}
+
+// CurrencyOrDefault is NewCurrency falling back to the default currency,
+// for callers that have already validated the code.
+func CurrencyOrDefault(code, locale string) *Currency {
+	c, err := NewCurrency(code, locale)
+	if err != nil {
+		c, _ = NewCurrency(DefaultCurrency, "")
+	}
+	return c
+}
+
+// FormatNumber groups an amount for the locale, rounded to the currency's
+// decimal places, without a symbol.
+func (c *Currency) FormatNumber(amount float64) string {
+	return c.printer.Sprint(number.Decimal(amount, number.Scale(c.Decimals)))
+}
+
+// Format writes an amount with the currency symbol, e.g. ₹1,50,000.00,
+// $1,234.50 or ¥1,500. Letter symbols such as CHF are followed by a space.
+func (c *Currency) Format(amount float64) string {
+	symbol := c.Symbol
+	if last := []rune(symbol); len(last) > 0 && unicode.IsLetter(last[len(last)-1]) {
+		symbol += " "
+	}
+	return symbol + c.FormatNumber(amount)
+}
+
+// FormatString formats an amount given as text, such as "275000.0" or
+// "2,890,000.00". Text that is not a number is returned after the symbol
+// unchanged.
+func (c *Currency) FormatString(amount string) string {
+	value, err := ParseAmount(amount)
+	if err != nil {
+		return c.Symbol + strings.TrimSpace(amount)
+	}
+	return c.Format(value)
+}
+
+// FormatMoney formats an exact amount. An amount tagged with another
+// currency is written in that currency, with this locale's grouping.
+func (c *Currency) FormatMoney(amount models.Money) string {
+	if code := amount.Currency(); code != "" && code != c.Code {
+		if other, err := NewCurrency(code, c.Locale); err == nil {
+			return other.Format(amount.Float64())
+		}
+	}
+	return c.Format(amount.Float64())
+}
@@ -1,8 +1,15 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 func GenerateReadableFilename(destination, startDate, endDate string, travelers int, customerName string) string {
@@ -15,6 +22,58 @@ func GenerateReadableFilename(destination, startDate, endDate string, travelers
 	return filename
 }
 
+// GenerateInvoiceFilename names a proforma invoice after its number, which
+// is unique, so invoices are never stored as versions of each other.
+func GenerateInvoiceFilename(number, customerName string) string {
+	return fmt.Sprintf("Proforma_Invoice_%s_%s.pdf", sanitizeForFilename(number), sanitizeForFilename(customerName))
+}
+
+// FileID returns the public identifier of a stored file. Documents are
+// stored as <uuid>.pdf; files written before that are identified by a hash
+// of their name, so clients never see server paths.
+func FileID(storedName string) string {
+	base := strings.TrimSuffix(storedName, filepath.Ext(storedName))
+	if isUUID(base) {
+		return base
+	}
+	sum := sha256.Sum256([]byte(storedName))
+	return hex.EncodeToString(sum[:16])
+}
+
+func IsValidFileID(id string) bool {
+	if isUUID(id) {
+		return true
+	}
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// ContentHash returns the hex SHA-256 of a document's bytes.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VersionedFilename adds a _vN suffix before the extension for N > 1.
+func VersionedFilename(filename string, version int) string {
+	if version <= 1 {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s_v%d%s", strings.TrimSuffix(filename, ext), version, ext)
+}
+
 func EnsureDirectory(dirPath string) error {
     if _, err := os.Stat(dirPath); os.IsNotExist(err) {
         return os.MkdirAll(dirPath, 0755)
@@ -28,15 +87,53 @@ func GetFileSize(filePath string) (string, error) {
 		return "", err
 	}
 	
-	size := info.Size()
-	
+	return FormatFileSize(info.Size()), nil
+}
+
+func FormatFileSize(size int64) string {
 	if size < 1024 {
-		return fmt.Sprintf("%d B", size), nil
+		return fmt.Sprintf("%d B", size)
 	} else if size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(size)/1024), nil
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
 	} else {
-		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024)), nil
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// ParseByteSize converts sizes such as "500MB" or "5GB" to bytes. An empty
+// string means no limit and returns 0.
+func ParseByteSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if size == "" {
+		return 0, nil
 	}
+
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(size, unit.suffix) {
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+
+	return int64(value * float64(multiplier)), nil
 }
 
 func sanitizeForFilename(s string) string {
@@ -2,29 +2,20 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// FormatCurrency formats an amount in the currency with the given ISO 4217
+// code, INR when empty or unknown.
 func FormatCurrency(amount float64, currency string) string {
-	if currency == "" {
-		currency = "₹"
-	}
-	return fmt.Sprintf("%s%.2f", currency, amount)
+	return CurrencyOrDefault(currency, "").Format(amount)
 }
 
 func FormatCurrencyString(amountStr string, currency string) string {
-	if currency == "" {
-		currency = "₹"
-	}
-	
-	amount, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil {
-		return fmt.Sprintf("%s%s", currency, amountStr)
-	}
-	
-	return fmt.Sprintf("%s%.2f", currency, amount)
+	return CurrencyOrDefault(currency, "").FormatString(amountStr)
 }
 
 func FormatDate(date interface{}) string {
@@ -38,61 +29,84 @@ func FormatDate(date interface{}) string {
     }
 }
 
+var dateFormats = []string{
+    "2006-01-02",
+    "2006-01-02T15:04:05Z",
+    "2006-01-02T15:04:05.000Z",
+    "02/01/2006",
+    "2006/01/02",
+}
+
 func formatDateWithLayout(dateStr string, layout string) string {
-    formats := []string{
-        "2006-01-02",
-        "2006-01-02T15:04:05Z",
-        "2006-01-02T15:04:05.000Z",
-        "02/01/2006",
-        "2006/01/02",
+    if t, err := ParseDate(dateStr); err == nil {
+        return t.Format(layout)
     }
     
-    for _, format := range formats {
+    return dateStr
+}
+
+// ParseDate reads a date in any format the templates accept, such as
+// 2006-01-02 or 02/01/2006.
+func ParseDate(dateStr string) (time.Time, error) {
+    for _, format := range dateFormats {
         if t, err := time.Parse(format, dateStr); err == nil {
-            return t.Format(layout)
+            return t, nil
         }
     }
     
-    return dateStr
+    return time.Time{}, fmt.Errorf("invalid date %q", dateStr)
 }
 
 func FormatDateShort(dateStr string) string {
     return formatDateWithLayout(dateStr, "2 Jan")
 }
 
+// CalculateDuration describes a trip counting both its first and last day,
+// e.g. "8 Days 7 Nights" for 2024-12-15 to 2024-12-22.
 func CalculateDuration(startDate, endDate string) string {
-	start, err1 := time.Parse("2006-01-02", startDate)
-	end, err2 := time.Parse("2006-01-02", endDate)
+	start, err1 := ParseDate(startDate)
+	end, err2 := ParseDate(endDate)
 	
-	if err1 != nil || err2 != nil {
+	if err1 != nil || err2 != nil || end.Before(start) {
 		return "N/A"
 	}
 	
-	days := int(end.Sub(start).Hours() / 24)
-	nights := days - 1
-	
-	if nights <= 0 {
-		return fmt.Sprintf("%d Day", days)
+	nights := int(math.Round(end.Sub(start).Hours() / 24))
+	if nights == 0 {
+		return "1 Day"
+	}
+	if nights == 1 {
+		return "2 Days 1 Night"
 	}
 	
-	return fmt.Sprintf("%d Days %d Nights", days, nights)
+	return fmt.Sprintf("%d Days %d Nights", nights+1, nights)
+}
+
+var timeFormats = []string{
+	"15:04",
+	"15:04:05",
+	"3:04 PM",
+	"3:04:05 PM",
 }
 
 func FormatTime(timeStr string) string {
-	formats := []string{
-		"15:04",
-		"15:04:05",
-		"3:04 PM",
-		"3:04:05 PM",
+	if t, err := ParseClock(timeStr); err == nil {
+		return t.Format("15:04")
 	}
 	
-	for _, format := range formats {
-		if t, err := time.Parse(format, timeStr); err == nil {
-			return t.Format("15:04")
+	return timeStr
+}
+
+// ParseClock reads a time of day such as 15:04 or 3:04 PM. The result is on
+// the zero date.
+func ParseClock(timeStr string) (time.Time, error) {
+	for _, format := range timeFormats {
+		if t, err := time.Parse(format, strings.TrimSpace(timeStr)); err == nil {
+			return t, nil
 		}
 	}
 	
-	return timeStr
+	return time.Time{}, fmt.Errorf("invalid time %q", timeStr)
 }
 
 func FormatTimeRange(start, end time.Time) string {
@@ -111,12 +125,23 @@ func TruncateText(text string, maxLength int) string {
 	return text[:maxLength-3] + "..."
 }
 
-func FormatPrice(price float64) string {
+func FormatPrice(price float64, currency string) string {
 	if price == 0 {
 		return "Free"
 	}
 	
-	return fmt.Sprintf("₹%.0f", price)
+	return CurrencyOrDefault(currency, "").Format(price)
+}
+
+// ParseAmount reads a payment amount written with or without thousands
+// separators, such as "2,890,000.00" or "82500.0".
+func ParseAmount(s string) (float64, error) {
+	cleaned := strings.NewReplacer(",", "", " ", "").Replace(strings.TrimSpace(s))
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
 }
 
 func ParseFloat(s string) float64 {
@@ -0,0 +1,75 @@
+package utils
+
+import "testing"
+
+func TestCalculateDuration(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       string
+	}{
+		{"2024-12-15", "2024-12-22", "8 Days 7 Nights"},
+		{"2025-03-10", "2025-03-20", "11 Days 10 Nights"},
+		{"2025-06-01", "2025-06-15", "15 Days 14 Nights"},
+		{"2025-03-10", "2025-03-12", "3 Days 2 Nights"},
+		{"2025-03-10", "2025-03-11", "2 Days 1 Night"},
+		{"2025-03-10", "2025-03-10", "1 Day"},
+		{"2025-03-30", "2025-04-02", "4 Days 3 Nights"},
+		{"2025-03-20", "2025-03-10", "N/A"},
+		{"soon", "2025-03-10", "N/A"},
+	}
+
+	for _, tt := range tests {
+		if got := CalculateDuration(tt.start, tt.end); got != tt.want {
+			t.Errorf("CalculateDuration(%q, %q) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		currency string
+		want     string
+	}{
+		{"rupees in lakh", 150000, "INR", "₹1,50,000.00"},
+		{"rupees in crore", 12345678.9, "INR", "₹1,23,45,678.90"},
+		{"default currency", 150000, "", "₹1,50,000.00"},
+		{"unknown currency", 150000, "XYZ", "₹1,50,000.00"},
+		{"dollars", 1234.5, "USD", "$1,234.50"},
+		{"yen has no decimals", 1500.4, "JPY", "¥1,500"},
+		{"letter symbol", 99.5, "CHF", "CHF 99.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCurrency(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("FormatCurrency(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCurrencyString(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		want     string
+	}{
+		{"plain number", "150000", "INR", "₹1,50,000.00"},
+		{"decimal", "275000.0", "INR", "₹2,75,000.00"},
+		{"western grouping", "2,890,000.00", "INR", "₹28,90,000.00"},
+		{"yen", "1500", "JPY", "¥1,500"},
+		{"not a number", "on request", "INR", "₹on request"},
+		{"not a number padded", " TBC ", "JPY", "¥TBC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCurrencyString(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("FormatCurrencyString(%q, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PaperSize is a portrait paper size in inches
+type PaperSize struct {
+	Width  float64
+	Height float64
+}
+
+var paperSizes = map[string]PaperSize{
+	"a3":      {Width: 11.69, Height: 16.54},
+	"a4":      {Width: 8.27, Height: 11.69},
+	"a5":      {Width: 5.83, Height: 8.27},
+	"letter":  {Width: 8.5, Height: 11},
+	"legal":   {Width: 8.5, Height: 14},
+	"tabloid": {Width: 11, Height: 17},
+}
+
+var lengthUnits = map[string]float64{
+	"in": 1,
+	"mm": 1 / 25.4,
+	"cm": 1 / 2.54,
+	"px": 1.0 / 96,
+	"pt": 1.0 / 72,
+}
+
+// ParsePaperSize resolves a named format (A4, Letter, ...) or a custom
+// "WxH" size such as "210mmx297mm" or "8.5x11" (inches when no unit).
+func ParsePaperSize(format string) (PaperSize, error) {
+	name := strings.ToLower(strings.TrimSpace(format))
+	if size, ok := paperSizes[name]; ok {
+		return size, nil
+	}
+
+	parts := strings.Split(name, "x")
+	if len(parts) != 2 {
+		return PaperSize{}, fmt.Errorf("unknown page format %q, expected one of A3, A4, A5, Letter, Legal, Tabloid or WxH", format)
+	}
+
+	width, err := ParseLength(parts[0])
+	if err != nil {
+		return PaperSize{}, fmt.Errorf("invalid page width in %q: %w", format, err)
+	}
+	height, err := ParseLength(parts[1])
+	if err != nil {
+		return PaperSize{}, fmt.Errorf("invalid page height in %q: %w", format, err)
+	}
+	if width <= 0 || height <= 0 {
+		return PaperSize{}, fmt.Errorf("page size %q must be positive", format)
+	}
+
+	return PaperSize{Width: width, Height: height}, nil
+}
+
+// ParseLength converts a CSS length (in, mm, cm, px, pt) to inches. A bare
+// number is treated as inches.
+func ParseLength(value string) (float64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, fmt.Errorf("empty length")
+	}
+
+	factor := 1.0
+	for unit, f := range lengthUnits {
+		if strings.HasSuffix(value, unit) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit))
+			factor = f
+			break
+		}
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length %q", value)
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("length %q must be a finite number", value)
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("length %q must not be negative", value)
+	}
+
+	return amount * factor, nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"1", 1, false},
+		{"0.5in", 0.5, false},
+		{"25.4mm", 1, false},
+		{"2.54 cm", 1, false},
+		{"96px", 1, false},
+		{"72PT", 1, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"-1mm", 0, true},
+		{"NaN", 0, true},
+		{"nanmm", 0, true},
+		{"Inf", 0, true},
+		{"+infin", 0, true},
+		{"-Infinity", 0, true},
+		{"1e400mm", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLength(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLength(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ParseLength(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePaperSize(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    PaperSize
+		wantErr bool
+	}{
+		{"A4", PaperSize{Width: 8.27, Height: 11.69}, false},
+		{" letter ", PaperSize{Width: 8.5, Height: 11}, false},
+		{"8.5x11", PaperSize{Width: 8.5, Height: 11}, false},
+		{"254mmx508mm", PaperSize{Width: 10, Height: 20}, false},
+		{"B5", PaperSize{}, true},
+		{"10x", PaperSize{}, true},
+		{"0x10", PaperSize{}, true},
+		{"nanx10", PaperSize{}, true},
+		{"10xinf", PaperSize{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParsePaperSize(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePaperSize(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if !tt.wantErr && (math.Abs(got.Width-tt.want.Width) > 1e-9 || math.Abs(got.Height-tt.want.Height) > 1e-9) {
+				t.Errorf("ParsePaperSize(%q) = %+v, want %+v", tt.format, got, tt.want)
+			}
+		})
+	}
+}
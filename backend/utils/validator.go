@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/KrishKoria/Vigovia/models"
@@ -11,8 +12,45 @@ import (
 
 var validate *validator.Validate
 
+var (
+	gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+	ifscPattern  = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+)
+
+// IsGSTIN reports whether s is shaped like a GST identification number:
+// state code, PAN, entity number, "Z" and a check character.
+func IsGSTIN(s string) bool {
+	return gstinPattern.MatchString(s)
+}
+
 func init() {
 	validate = validator.New()
+	// Report fields by their JSON names so error paths match the request body.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// Money is validated as its decimal text, so "required" sees a missing
+	// amount and "money" sees input that could not be read.
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(models.Money).String()
+	}, models.Money{})
+	validate.RegisterValidation("money", func(fl validator.FieldLevel) bool {
+		money, err := models.ParseMoney(fl.Field().String())
+		return err == nil && !money.IsNegative()
+	})
+	validate.RegisterValidation("gstin", func(fl validator.FieldLevel) bool {
+		return IsGSTIN(fl.Field().String())
+	})
+	validate.RegisterValidation("ifsc", func(fl validator.FieldLevel) bool {
+		return ifscPattern.MatchString(fl.Field().String())
+	})
 }
 
 func ValidateStruct(s interface{}) []models.APIError {
@@ -22,7 +60,7 @@ func ValidateStruct(s interface{}) []models.APIError {
 	if err != nil {
 		for _, err := range err.(validator.ValidationErrors) {
 			apiError := models.APIError{
-				Field:   getJSONFieldName(s, err.Field()),
+				Field:   fieldPath(err),
 				Message: getValidationMessage(err),
 				Code:    "VALIDATION_ERROR",
 			}
@@ -33,24 +71,14 @@ func ValidateStruct(s interface{}) []models.APIError {
 	return errors
 }
 
-func getJSONFieldName(s interface{}, fieldName string) string {
-	t := reflect.TypeOf(s)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+// fieldPath returns the full JSON path of a failed field, such as
+// itinerary.days[2].activities[0].location, without the root type name.
+func fieldPath(err validator.FieldError) string {
+	namespace := err.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
 	}
-	
-	field, found := t.FieldByName(fieldName)
-	if !found {
-		return fieldName
-	}
-	
-	jsonTag := field.Tag.Get("json")
-	if jsonTag == "" {
-		return fieldName
-	}
-	
-	parts := strings.Split(jsonTag, ",")
-	return parts[0]
+	return namespace
 }
 
 func getValidationMessage(err validator.FieldError) string {
@@ -83,8 +111,22 @@ func getValidationMessage(err validator.FieldError) string {
 		return fmt.Sprintf("Must be one of: %s", err.Param())
 	case "url":
 		return "Must be a valid URL"
+	case "hexcolor":
+		return "Must be a valid hex color (e.g. #321e5d)"
 	case "uuid":
 		return "Must be a valid UUID"
+	case "iso4217":
+		return "Must be an ISO 4217 currency code (e.g. INR, USD)"
+	case "bcp47_language_tag":
+		return "Must be a locale such as en-IN or de-DE"
+	case "money":
+		return "Must be a non-negative amount such as 1500 or 1,500.00"
+	case "gstin":
+		return "Must be a 15-character GSTIN such as 29ABCDE1234F1Z5"
+	case "ifsc":
+		return "Must be an 11-character IFSC such as HDFC0001234"
+	case "bic":
+		return "Must be a SWIFT/BIC code such as HDFCINBB"
 	case "alphanum":
 		return "Must contain only letters and numbers"
 	case "alpha":
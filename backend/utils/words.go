@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KrishKoria/Vigovia/models"
+	"golang.org/x/text/language"
+)
+
+// Numbering systems for amounts in words.
+const (
+	NumberingIndian        = "indian"
+	NumberingInternational = "international"
+)
+
+// Languages for amounts in words.
+const (
+	WordsEnglish = "en"
+	WordsHindi   = "hi"
+)
+
+// currencyName holds the words for a currency's unit and subunit.
+type currencyName struct {
+	major, majorPlural           string
+	minor, minorPlural           string
+	hindiMajor, hindiMajorPlural string
+	hindiMinor, hindiMinorPlural string
+}
+
+var currencyNames = map[string]currencyName{
+	"INR": {"Rupee", "Rupees", "Paisa", "Paise", "रुपया", "रुपये", "पैसा", "पैसे"},
+	"USD": {"US Dollar", "US Dollars", "Cent", "Cents", "अमेरिकी डॉलर", "अमेरिकी डॉलर", "सेंट", "सेंट"},
+	"EUR": {"Euro", "Euros", "Cent", "Cents", "यूरो", "यूरो", "सेंट", "सेंट"},
+	"GBP": {"Pound Sterling", "Pounds Sterling", "Penny", "Pence", "पाउंड", "पाउंड", "पेंस", "पेंस"},
+	"AUD": {"Australian Dollar", "Australian Dollars", "Cent", "Cents", "ऑस्ट्रेलियाई डॉलर", "ऑस्ट्रेलियाई डॉलर", "सेंट", "सेंट"},
+	"CAD": {"Canadian Dollar", "Canadian Dollars", "Cent", "Cents", "कनाडाई डॉलर", "कनाडाई डॉलर", "सेंट", "सेंट"},
+	"SGD": {"Singapore Dollar", "Singapore Dollars", "Cent", "Cents", "सिंगापुर डॉलर", "सिंगापुर डॉलर", "सेंट", "सेंट"},
+	"AED": {"UAE Dirham", "UAE Dirhams", "Fils", "Fils", "दिरहम", "दिरहम", "फ़िल्स", "फ़िल्स"},
+	"CHF": {"Swiss Franc", "Swiss Francs", "Centime", "Centimes", "स्विस फ़्रैंक", "स्विस फ़्रैंक", "सेंटीम", "सेंटीम"},
+	"JPY": {"Japanese Yen", "Japanese Yen", "", "", "जापानी येन", "जापानी येन", "", ""},
+	"THB": {"Thai Baht", "Thai Baht", "Satang", "Satang", "थाई बाट", "थाई बाट", "सतांग", "सतांग"},
+	"KWD": {"Kuwaiti Dinar", "Kuwaiti Dinars", "Fils", "Fils", "कुवैती दीनार", "कुवैती दीनार", "फ़िल्स", "फ़िल्स"},
+	"NPR": {"Nepalese Rupee", "Nepalese Rupees", "Paisa", "Paise", "नेपाली रुपया", "नेपाली रुपये", "पैसा", "पैसे"},
+	"LKR": {"Sri Lankan Rupee", "Sri Lankan Rupees", "Cent", "Cents", "श्रीलंकाई रुपया", "श्रीलंकाई रुपये", "सेंट", "सेंट"},
+}
+
+var (
+	englishOnes = []string{
+		"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine",
+		"Ten", "Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen",
+		"Seventeen", "Eighteen", "Nineteen",
+	}
+	englishTens = []string{
+		"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety",
+	}
+
+	// Hindi numbers below a hundred are irregular and cannot be composed
+	// from tens and units.
+	hindiNumbers = [100]string{
+		"शून्य", "एक", "दो", "तीन", "चार", "पाँच", "छह", "सात", "आठ", "नौ",
+		"दस", "ग्यारह", "बारह", "तेरह", "चौदह", "पंद्रह", "सोलह", "सत्रह", "अठारह", "उन्नीस",
+		"बीस", "इक्कीस", "बाईस", "तेईस", "चौबीस", "पच्चीस", "छब्बीस", "सत्ताईस", "अट्ठाईस", "उनतीस",
+		"तीस", "इकतीस", "बत्तीस", "तैंतीस", "चौंतीस", "पैंतीस", "छत्तीस", "सैंतीस", "अड़तीस", "उनतालीस",
+		"चालीस", "इकतालीस", "बयालीस", "तैंतालीस", "चवालीस", "पैंतालीस", "छियालीस", "सैंतालीस", "अड़तालीस", "उनचास",
+		"पचास", "इक्यावन", "बावन", "तिरपन", "चौवन", "पचपन", "छप्पन", "सत्तावन", "अट्ठावन", "उनसठ",
+		"साठ", "इकसठ", "बासठ", "तिरसठ", "चौंसठ", "पैंसठ", "छियासठ", "सड़सठ", "अड़सठ", "उनहत्तर",
+		"सत्तर", "इकहत्तर", "बहत्तर", "तिहत्तर", "चौहत्तर", "पचहत्तर", "छिहत्तर", "सतहत्तर", "अठहत्तर", "उन्यासी",
+		"अस्सी", "इक्यासी", "बयासी", "तिरासी", "चौरासी", "पचासी", "छियासी", "सत्तासी", "अट्ठासी", "नवासी",
+		"नब्बे", "इक्यानवे", "बानवे", "तिरानवे", "चौरानवे", "पचानवे", "छियानवे", "सत्तानवे", "अट्ठानवे", "निन्यानवे",
+	}
+)
+
+// scale is one named power of ten in a numbering system.
+type scale struct {
+	value int64
+	name  string
+}
+
+var (
+	indianScales = []scale{{10000000, "Crore"}, {100000, "Lakh"}, {1000, "Thousand"}}
+	hindiScales  = []scale{{10000000, "करोड़"}, {100000, "लाख"}, {1000, "हज़ार"}}
+
+	internationalScales = []scale{
+		{1000000000000, "Trillion"}, {1000000000, "Billion"}, {1000000, "Million"}, {1000, "Thousand"},
+	}
+)
+
+// AmountInWords writes an amount the way invoices print totals, such as
+// "Rupees One Lakh Fifty Thousand Only" or "एक लाख पचास हज़ार रुपये मात्र".
+// Subunits are written after the unit ("and Fifty Paise"). English uses the
+// Indian (lakh, crore) or international (million, billion) numbering
+// system; Hindi always counts in lakh and crore. An empty lang follows the
+// currency's locale and an empty system its currency.
+func AmountInWords(amount models.Money, c *Currency, lang, system string) string {
+	if code := amount.Currency(); code != "" && code != c.Code {
+		if other, err := NewCurrency(code, c.Locale); err == nil {
+			c = other
+		}
+	}
+	if lang != WordsEnglish && lang != WordsHindi {
+		lang = WordsEnglish
+		if tag, err := language.Parse(c.Locale); err == nil {
+			if base, _ := tag.Base(); base.String() == WordsHindi {
+				lang = WordsHindi
+			}
+		}
+	}
+	if system != NumberingIndian && system != NumberingInternational {
+		system = defaultNumbering(c)
+	}
+
+	if rescaled, err := amount.Rescale(c.Decimals); err == nil {
+		amount = rescaled
+	}
+
+	minor := amount.Minor()
+	negative := minor < 0
+	if negative {
+		minor = -minor
+	}
+	unit := int64(1)
+	for i := 0; i < amount.Scale(); i++ {
+		unit *= 10
+	}
+	major, sub := minor/unit, minor%unit
+
+	name, known := currencyNames[c.Code]
+	if lang == WordsHindi {
+		return hindiAmount(major, sub, unit, negative, name, known, c.Code)
+	}
+	return englishAmount(major, sub, unit, negative, name, known, c.Code, system)
+}
+
+// defaultNumbering counts rupees, and amounts for Indian locales, in lakh
+// and crore.
+func defaultNumbering(c *Currency) string {
+	if c.Code == "INR" {
+		return NumberingIndian
+	}
+	if tag, err := language.Parse(c.Locale); err == nil {
+		if region, _ := tag.Region(); region.String() == "IN" {
+			return NumberingIndian
+		}
+	}
+	return NumberingInternational
+}
+
+func englishAmount(major, sub, unit int64, negative bool, name currencyName, known bool, code, system string) string {
+	scales := internationalScales
+	if system == NumberingIndian {
+		scales = indianScales
+	}
+
+	var words []string
+	if negative {
+		words = append(words, "Minus")
+	}
+
+	switch {
+	case !known:
+		words = append(words, code)
+	case major == 1:
+		words = append(words, name.major)
+	default:
+		words = append(words, name.majorPlural)
+	}
+	words = append(words, englishNumber(major, scales))
+
+	if sub > 0 {
+		switch {
+		case known && name.minor != "":
+			minorName := name.minorPlural
+			if sub == 1 {
+				minorName = name.minor
+			}
+			words = append(words, "and", englishNumber(sub, scales), minorName)
+		default:
+			words = append(words, "and", fmt.Sprintf("%d/%d", sub, unit))
+		}
+	}
+
+	return strings.Join(append(words, "Only"), " ")
+}
+
+func englishNumber(n int64, scales []scale) string {
+	if n == 0 {
+		return englishOnes[0]
+	}
+
+	var words []string
+	for _, s := range scales {
+		if n >= s.value {
+			words = append(words, englishNumber(n/s.value, scales), s.name)
+			n %= s.value
+		}
+	}
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "Hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	case n >= 20:
+		words = append(words, englishTens[n/10])
+	case n > 0:
+		words = append(words, englishOnes[n])
+	}
+	return strings.Join(words, " ")
+}
+
+func hindiAmount(major, sub, unit int64, negative bool, name currencyName, known bool, code string) string {
+	var words []string
+	if negative {
+		words = append(words, "ऋण")
+	}
+
+	majorName := code
+	switch {
+	case known && major == 1:
+		majorName = name.hindiMajor
+	case known:
+		majorName = name.hindiMajorPlural
+	}
+	words = append(words, hindiNumber(major), majorName)
+
+	if sub > 0 {
+		if known && name.hindiMinor != "" {
+			minorName := name.hindiMinorPlural
+			if sub == 1 {
+				minorName = name.hindiMinor
+			}
+			words = append(words, "और", hindiNumber(sub), minorName)
+		} else {
+			words = append(words, "और", fmt.Sprintf("%d/%d", sub, unit))
+		}
+	}
+
+	return strings.Join(append(words, "मात्र"), " ")
+}
+
+func hindiNumber(n int64) string {
+	if n == 0 {
+		return hindiNumbers[0]
+	}
+
+	var words []string
+	for _, s := range hindiScales {
+		if n >= s.value {
+			words = append(words, hindiNumber(n/s.value), s.name)
+			n %= s.value
+		}
+	}
+	if n >= 100 {
+		words = append(words, hindiNumbers[n/100], "सौ")
+		n %= 100
+	}
+	if n > 0 {
+		words = append(words, hindiNumbers[n])
+	}
+	return strings.Join(words, " ")
+}
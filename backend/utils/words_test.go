@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/KrishKoria/Vigovia/models"
+)
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		locale   string
+		lang     string
+		system   string
+		want     string
+	}{
+		{"zero", "0", "INR", "", "", "", "Rupees Zero Only"},
+		{"one rupee", "1", "INR", "", "", "", "Rupee One Only"},
+		{"lakh and crore", "12345678", "INR", "", "", "", "Rupees One Crore Twenty-Three Lakh Forty-Five Thousand Six Hundred Seventy-Eight Only"},
+		{"paise", "150000.50", "INR", "", "", "", "Rupees One Lakh Fifty Thousand and Fifty Paise Only"},
+		{"one paisa", "10.01", "INR", "", "", "", "Rupees Ten and One Paisa Only"},
+		{"international rupees", "1500000", "INR", "", "", NumberingInternational, "Rupees One Million Five Hundred Thousand Only"},
+		{"dollars default to international", "2500000.75", "USD", "", "", "", "US Dollars Two Million Five Hundred Thousand and Seventy-Five Cents Only"},
+		{"Indian locale counts in lakh", "150000", "USD", "en-IN", "", "", "US Dollars One Lakh Fifty Thousand Only"},
+		{"no subunit", "1500", "JPY", "", "", "", "Japanese Yen One Thousand Five Hundred Only"},
+		{"three decimals", "1.250", "KWD", "", "", "", "Kuwaiti Dinar One and Two Hundred Fifty Fils Only"},
+		{"unnamed currency", "12.5", "BRL", "", "", "", "BRL Twelve and 50/100 Only"},
+		{"negative", "-20", "INR", "", "", "", "Minus Rupees Twenty Only"},
+		{"teens and round tens", "1019.90", "GBP", "", "", "", "Pounds Sterling One Thousand Nineteen and Ninety Pence Only"},
+		{"hindi", "150000.50", "INR", "", WordsHindi, "", "एक लाख पचास हज़ार रुपये और पचास पैसे मात्र"},
+		{"hindi one rupee", "1", "INR", "", WordsHindi, "", "एक रुपया मात्र"},
+		{"hindi from locale", "2599", "INR", "hi-IN", "", "", "दो हज़ार पाँच सौ निन्यानवे रुपये मात्र"},
+		{"hindi crore", "-30000000", "INR", "", WordsHindi, NumberingInternational, "ऋण तीन करोड़ रुपये मात्र"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := models.ParseMoney(tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := CurrencyOrDefault(tt.currency, tt.locale)
+
+			if got := AmountInWords(amount, c, tt.lang, tt.system); got != tt.want {
+				t.Errorf("AmountInWords(%s %s) =\n%s\nwant\n%s", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountInWordsUsesTheAmountsCurrency(t *testing.T) {
+	amount, _ := models.ParseMoney("5")
+	got := AmountInWords(amount.WithCurrency("EUR"), CurrencyOrDefault("INR", ""), "", "")
+	if want := "Euros Five Only"; got != want {
+		t.Errorf("AmountInWords() = %q, want %q", got, want)
+	}
+}